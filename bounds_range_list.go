@@ -0,0 +1,339 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"errors"
+	"fmt"
+	"github.com/markphelps/optional"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RangeList are Bounds consisting of several disjoint intervals, e.g. to accept a value of exactly 0 or any value
+// between 15 and 25. Range lists support the set operations union, intersection and complement and can be parsed from
+// a comma-separated list of Nagios ranges. Open interval ends are denoted with parentheses, e.g. "(0:15)", which are
+// only needed to express complements of closed intervals. As Nagios does not support multiple intervals, the widest
+// interval gets used when being rendered as a single Nagios range.
+type RangeList interface {
+	Bounds
+
+	Specifier() string
+	IsNagiosRange() bool
+	Union(Bounds) RangeList
+	Intersect(Bounds) RangeList
+	Complement() RangeList
+}
+
+type rangeList struct {
+	intervals []interval
+}
+
+type interval struct {
+	lower     float64
+	upper     float64
+	lowerOpen bool
+	upperOpen bool
+}
+
+// NewRangeList instantiates a new RangeList, which matches all values matched by any of the given bounds
+func NewRangeList(bounds ...Bounds) RangeList {
+	var intervals []interval
+	for _, value := range bounds {
+		intervals = append(intervals, boundsToIntervals(value)...)
+	}
+
+	return &rangeList{intervals: normalizeIntervals(intervals)}
+}
+
+// NewRangeListFromNagiosRanges is a helper method, which constructs a new RangeList from a comma-separated list of
+// Nagios range specifiers like "0:0,15:25". Each specifier may be inverted using '@' or be enclosed by parentheses.
+func NewRangeListFromNagiosRanges(specifier string) (RangeList, error) {
+	var intervals []interval
+
+	for _, part := range strings.Split(specifier, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, errors.New("range list must not contain empty ranges")
+		}
+
+		lowerOpen := strings.HasPrefix(part, "(")
+		upperOpen := strings.HasSuffix(part, ")")
+		part = strings.TrimSuffix(strings.TrimPrefix(part, "("), ")")
+
+		if (lowerOpen || upperOpen) && strings.HasPrefix(part, "@") {
+			return nil, fmt.Errorf("range specifier [%s] can not be both inverted and open", part)
+		}
+
+		bounds, err := NewBoundsFromNagiosRange(part)
+		if err != nil {
+			return nil, err
+		}
+		if bounds.Lower().OrElse(math.Inf(-1)) > bounds.Upper().OrElse(math.Inf(1)) {
+			return nil, fmt.Errorf("range specifier [%s] must not end before it starts", part)
+		}
+
+		partIntervals := boundsToIntervals(bounds)
+		for index := range partIntervals {
+			partIntervals[index].lowerOpen = partIntervals[index].lowerOpen || lowerOpen
+			partIntervals[index].upperOpen = partIntervals[index].upperOpen || upperOpen
+		}
+		intervals = append(intervals, partIntervals...)
+	}
+
+	return &rangeList{intervals: normalizeIntervals(intervals)}, nil
+}
+
+// warnDegradedThreshold adds a warning, if the given threshold is a RangeList which can not be expressed exactly as a
+// single Nagios range within the performance data of the given metric
+func warnDegradedThreshold(warnings WarningCollection, metric Metric, threshold OptionalBounds) {
+	bounds, err := threshold.Get()
+	if err != nil {
+		return
+	}
+
+	if rangeList, ok := bounds.(RangeList); ok && !rangeList.IsNagiosRange() {
+		warnings.Add(NewWarning("nagopher: threshold [%s] of metric [%s] degraded to nagios range [%s] in performance data",
+			rangeList.Specifier(), metric.Name(), rangeList.ToNagiosRange()))
+	}
+}
+
+func boundsToIntervals(bounds Bounds) []interval {
+	switch value := bounds.(type) {
+	case *rangeList:
+		return append([]interval{}, value.intervals...)
+	case *scheduledBounds:
+		return boundsToIntervals(value.current())
+	case ScheduledBounds:
+		return boundsToIntervals(value.At(time.Now()))
+	}
+
+	lower := bounds.Lower().OrElse(math.Inf(-1))
+	upper := bounds.Upper().OrElse(math.Inf(1))
+	if !bounds.IsInverted() {
+		return []interval{newInterval(lower, upper, false, false)}
+	}
+
+	return []interval{
+		newInterval(math.Inf(-1), lower, true, true),
+		newInterval(upper, math.Inf(1), true, true),
+	}
+}
+
+func newInterval(lower float64, upper float64, lowerOpen bool, upperOpen bool) interval {
+	return interval{
+		lower:     lower,
+		upper:     upper,
+		lowerOpen: lowerOpen || math.IsInf(lower, -1),
+		upperOpen: upperOpen || math.IsInf(upper, 1),
+	}
+}
+
+func (i interval) isEmpty() bool {
+	return i.lower > i.upper || (i.lower == i.upper && (i.lowerOpen || i.upperOpen))
+}
+
+func (i interval) contains(value float64) bool {
+	aboveLower := value > i.lower || (value == i.lower && !i.lowerOpen)
+	belowUpper := value < i.upper || (value == i.upper && !i.upperOpen)
+
+	return aboveLower && belowUpper
+}
+
+func (i interval) String() string {
+	var result string
+
+	if math.IsInf(i.lower, -1) {
+		result += "~"
+	} else {
+		if i.lowerOpen {
+			result += "("
+		}
+		result += strconv.FormatFloat(i.lower, 'f', -1, strconv.IntSize)
+	}
+
+	result += ":"
+	if !math.IsInf(i.upper, 1) {
+		result += strconv.FormatFloat(i.upper, 'f', -1, strconv.IntSize)
+		if i.upperOpen {
+			result += ")"
+		}
+	}
+
+	return result
+}
+
+// normalizeIntervals sorts the given intervals, drops empty ones and merges overlapping or adjacent intervals
+func normalizeIntervals(intervals []interval) []interval {
+	var candidates []interval
+	for _, value := range intervals {
+		if !value.isEmpty() {
+			candidates = append(candidates, value)
+		}
+	}
+
+	sort.SliceStable(candidates, func(a int, b int) bool {
+		if candidates[a].lower != candidates[b].lower {
+			return candidates[a].lower < candidates[b].lower
+		}
+		return !candidates[a].lowerOpen && candidates[b].lowerOpen
+	})
+
+	var result []interval
+	for _, value := range candidates {
+		if len(result) == 0 {
+			result = append(result, value)
+			continue
+		}
+
+		last := &result[len(result)-1]
+		if value.lower > last.upper || (value.lower == last.upper && value.lowerOpen && last.upperOpen) {
+			result = append(result, value)
+			continue
+		}
+
+		if value.upper > last.upper {
+			last.upper, last.upperOpen = value.upper, value.upperOpen
+		} else if value.upper == last.upper {
+			last.upperOpen = last.upperOpen && value.upperOpen
+		}
+	}
+
+	return result
+}
+
+func (l rangeList) Union(bounds Bounds) RangeList {
+	return &rangeList{intervals: normalizeIntervals(append(append([]interval{}, l.intervals...), boundsToIntervals(bounds)...))}
+}
+
+func (l rangeList) Intersect(bounds Bounds) RangeList {
+	return l.Complement().Union(NewRangeList(bounds).Complement()).Complement()
+}
+
+func (l rangeList) Complement() RangeList {
+	var result []interval
+
+	lower, lowerOpen := math.Inf(-1), true
+	for _, value := range l.intervals {
+		result = append(result, newInterval(lower, value.lower, lowerOpen, !value.lowerOpen))
+		lower, lowerOpen = value.upper, !value.upperOpen
+	}
+	result = append(result, newInterval(lower, math.Inf(1), lowerOpen, true))
+
+	return &rangeList{intervals: normalizeIntervals(result)}
+}
+
+func (l rangeList) Specifier() string {
+	if len(l.intervals) == 0 {
+		return "@~:"
+	}
+
+	parts := make([]string, 0, len(l.intervals))
+	for _, value := range l.intervals {
+		parts = append(parts, value.String())
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// IsNagiosRange returns true, if the range list can be expressed as a single Nagios range without losing precision
+func (l rangeList) IsNagiosRange() bool {
+	switch len(l.intervals) {
+	case 0:
+		return true
+	case 1:
+		return !l.intervals[0].hasOpenFiniteEnd()
+	case 2:
+		return math.IsInf(l.intervals[0].lower, -1) && math.IsInf(l.intervals[1].upper, 1) &&
+			l.intervals[0].upperOpen && l.intervals[1].lowerOpen
+	}
+
+	return false
+}
+
+func (i interval) hasOpenFiniteEnd() bool {
+	return (i.lowerOpen && !math.IsInf(i.lower, -1)) || (i.upperOpen && !math.IsInf(i.upper, 1))
+}
+
+// nagiosBounds returns the single Bounds, which represent the range list best. Range lists matching no values at all
+// are represented by an inverted infinite range, the complement of a single interval by an inverted range and
+// everything else by the widest interval.
+func (l rangeList) nagiosBounds() Bounds {
+	if len(l.intervals) == 0 {
+		return NewBounds(InvertedBounds(true), LowerBound(math.Inf(-1)), UpperBound(math.Inf(1)))
+	}
+
+	if len(l.intervals) == 2 && l.IsNagiosRange() {
+		return NewBounds(InvertedBounds(true), LowerBound(l.intervals[0].upper), UpperBound(l.intervals[1].lower))
+	}
+
+	widest := l.intervals[0]
+	for _, value := range l.intervals[1:] {
+		if value.upper-value.lower > widest.upper-widest.lower {
+			widest = value
+		}
+	}
+
+	return NewBounds(LowerBound(widest.lower), UpperBound(widest.upper))
+}
+
+func (l rangeList) String() string {
+	return "inside ranges " + l.Specifier()
+}
+
+func (l rangeList) ViolationHint() string {
+	return "outside ranges " + l.Specifier()
+}
+
+func (l rangeList) ToNagiosRange() string {
+	if len(l.intervals) == 0 {
+		return "@~:"
+	}
+
+	return l.nagiosBounds().ToNagiosRange()
+}
+
+func (l rangeList) Match(value float64) bool {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return false
+	}
+
+	for _, interval := range l.intervals {
+		if interval.contains(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l rangeList) IsInverted() bool {
+	return l.nagiosBounds().IsInverted()
+}
+
+func (l rangeList) Lower() optional.Float64 {
+	return l.nagiosBounds().Lower()
+}
+
+func (l rangeList) Upper() optional.Float64 {
+	return l.nagiosBounds().Upper()
+}
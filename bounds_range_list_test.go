@@ -0,0 +1,168 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewRangeListFromNagiosRanges(t *testing.T) {
+	// given
+	specifiers := map[string]string{
+		"0:0,15:25":    "0:0,15:25",
+		"15:25, 0:0":   "0:0,15:25",
+		"10:20,15:30":  "10:30",
+		"10:20,20:30":  "10:30",
+		"(10:20),20:":  "(10:",
+		"@10:20":       "~:10),(20:",
+		"~:5,(5:10)":   "~:10)",
+		"(10:20),@0:5": "~:0),(5:",
+	}
+
+	for specifier, expected := range specifiers {
+		// when
+		rangeList, err := NewRangeListFromNagiosRanges(specifier)
+
+		// then
+		assert.NoError(t, err, specifier)
+		assert.Equal(t, expected, rangeList.Specifier(), specifier)
+	}
+}
+
+func TestNewRangeListFromNagiosRanges_Invalid(t *testing.T) {
+	for _, specifier := range []string{"a:b", "0:0,", "20:10", "(@10:20)", "1:2:3"} {
+		// when
+		_, err := NewRangeListFromNagiosRanges(specifier)
+
+		// then
+		assert.Error(t, err, specifier)
+	}
+}
+
+func TestRangeList_Match(t *testing.T) {
+	// given
+	rangeList, _ := NewRangeListFromNagiosRanges("0:0,15:25,(30:40)")
+
+	// when
+	results := []bool{
+		rangeList.Match(0), rangeList.Match(15), rangeList.Match(25), rangeList.Match(35),
+		rangeList.Match(-1), rangeList.Match(10), rangeList.Match(30), rangeList.Match(40),
+		rangeList.Match(math.NaN()), rangeList.Match(math.Inf(1)),
+	}
+
+	// then
+	assert.Equal(t, []bool{true, true, true, true, false, false, false, false, false, false}, results)
+	assert.Equal(t, "inside ranges 0:0,15:25,(30:40)", rangeList.String())
+	assert.Equal(t, "outside ranges 0:0,15:25,(30:40)", rangeList.ViolationHint())
+}
+
+func TestRangeList_SetOperations(t *testing.T) {
+	// given
+	rangeList, _ := NewRangeListFromNagiosRanges("0:10,20:30")
+	bounds, _ := NewBoundsFromNagiosRange("5:25")
+	invertedBounds, _ := NewBoundsFromNagiosRange("@5:25")
+
+	// when
+	union := rangeList.Union(bounds)
+	intersection := rangeList.Intersect(bounds)
+	invertedIntersection := rangeList.Intersect(invertedBounds)
+	complement := rangeList.Complement()
+	empty := rangeList.Intersect(complement)
+
+	// then
+	assert.Equal(t, "0:30", union.Specifier())
+	assert.Equal(t, "5:10,20:25", intersection.Specifier())
+	assert.Equal(t, "0:5),(25:30", invertedIntersection.Specifier())
+	assert.Equal(t, "~:0),(10:20),(30:", complement.Specifier())
+	assert.Equal(t, "0:10,20:30", complement.Complement().Specifier())
+	assert.Equal(t, "@~:", empty.Specifier())
+	assert.False(t, empty.Match(0))
+	assert.Equal(t, "~:", empty.Complement().Specifier())
+}
+
+func TestRangeList_ToNagiosRange(t *testing.T) {
+	// given
+	specifiers := map[string][]interface{}{
+		"10:20":          {"10:20", true},
+		"~:20":           {"~:20", true},
+		"@10:20":         {"@10:20", true},
+		"0:0,15:25":      {"15:25", false},
+		"(10:20)":        {"10:20", false},
+		"0:10,20:30,(5:": {"", true},
+	}
+
+	for specifier, expected := range specifiers {
+		// when
+		rangeList, _ := NewRangeListFromNagiosRanges(specifier)
+
+		// then
+		assert.Equal(t, expected[0], rangeList.ToNagiosRange(), specifier)
+		assert.Equal(t, expected[1], rangeList.IsNagiosRange(), specifier)
+	}
+
+	// given
+	empty := NewRangeList()
+
+	// then
+	assert.Equal(t, "@~:", empty.ToNagiosRange())
+	assert.True(t, empty.IsInverted())
+}
+
+func TestNewRangeList_ScheduledBounds(t *testing.T) {
+	// given
+	now := time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC)
+	nightWindow, _ := ParseWeeklyWindow("22:00-06:00", time.UTC)
+	bounds := NewScheduledBounds(NewBounds(UpperBound(10)), BoundsDuring(nightWindow, NewBounds(UpperBound(50))),
+		ScheduledBoundsClock(func() time.Time { return now }))
+
+	// when
+	rangeList1 := NewRangeList(bounds)
+	now = now.Add(11 * time.Hour)
+	rangeList2 := NewRangeList(bounds)
+
+	// then
+	assert.Equal(t, "~:10", rangeList1.ToNagiosRange())
+	assert.Equal(t, "~:50", rangeList2.ToNagiosRange())
+}
+
+func TestBaseCheck_RangeListThreshold(t *testing.T) {
+	// given
+	threshold, _ := NewRangeListFromNagiosRanges("0:0,15:25")
+	warningThreshold := Bounds(threshold)
+	check := NewCheck("check", NewSummarizer())
+	check.AttachResources(&mockMetricsResource{NewResource(), []Metric{
+		MustNewNumericMetric("temperature", 10, "", nil, "temperature"),
+	}})
+	check.AttachContexts(NewScalarContext("temperature", &warningThreshold, nil))
+	warnings := NewWarningCollection()
+
+	// when
+	check.Run(warnings)
+
+	// then
+	assert.Equal(t, StateWarning(), check.State())
+	assert.Equal(t, "temperature is 10 (outside ranges 0:0,15:25)", check.Summary())
+	assert.Equal(t, []string{"temperature=10;15:25"}, perfDataStrings(check.PerfData()))
+	assert.Equal(t, []string{
+		"nagopher: threshold [0:0,15:25] of metric [temperature] degraded to nagios range [15:25] in performance data",
+	}, warnings.GetWarningStrings())
+}
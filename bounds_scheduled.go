@@ -0,0 +1,132 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/markphelps/optional"
+	"time"
+)
+
+// ScheduledBounds are Bounds, which change depending on the current time by using a list of ScheduleWindow instances.
+// The first window containing the evaluation time determines the effective bounds, falling back to the default bounds.
+// When being used as regular Bounds, the current time of the clock set by ScheduledBoundsClock() is used for resolving
+// the effective bounds.
+type ScheduledBounds interface {
+	Bounds
+
+	At(t time.Time) Bounds
+}
+
+// ScheduledBoundsOpt is a type alias for functional options used by NewScheduledBounds()
+type ScheduledBoundsOpt func(*scheduledBounds)
+
+type scheduledBounds struct {
+	defaultBounds Bounds
+	rules         []scheduledBoundsRule
+	clock         func() time.Time
+}
+
+type scheduledBoundsRule struct {
+	window ScheduleWindow
+	bounds Bounds
+}
+
+// NewScheduledBounds instantiates new ScheduledBounds with the given default bounds and functional options
+func NewScheduledBounds(defaultBounds Bounds, options ...ScheduledBoundsOpt) ScheduledBounds {
+	scheduledBounds := &scheduledBounds{
+		defaultBounds: defaultBounds,
+	}
+
+	for _, option := range options {
+		option(scheduledBounds)
+	}
+
+	return scheduledBounds
+}
+
+// BoundsDuring is a functional option for NewScheduledBounds(), which uses the given bounds while the window is active
+func BoundsDuring(window ScheduleWindow, bounds Bounds) ScheduledBoundsOpt {
+	return func(b *scheduledBounds) {
+		b.rules = append(b.rules, scheduledBoundsRule{window: window, bounds: bounds})
+	}
+}
+
+// ScheduledBoundsClock is a functional option for NewScheduledBounds(), which replaces the clock being used when the
+// bounds are not resolved by a context, e.g. within RangeList operations. It defaults to time.Now().
+func ScheduledBoundsClock(clock func() time.Time) ScheduledBoundsOpt {
+	return func(b *scheduledBounds) {
+		b.clock = clock
+	}
+}
+
+func resolveScheduledBounds(optionalBounds OptionalBounds, t time.Time) OptionalBounds {
+	if bounds, err := optionalBounds.Get(); err == nil {
+		if scheduledBounds, ok := bounds.(ScheduledBounds); ok {
+			return NewOptionalBounds(scheduledBounds.At(t))
+		}
+	}
+
+	return optionalBounds
+}
+
+func (b scheduledBounds) At(t time.Time) Bounds {
+	for _, rule := range b.rules {
+		if rule.window.Contains(t) {
+			return rule.bounds
+		}
+	}
+
+	return b.defaultBounds
+}
+
+func (b scheduledBounds) current() Bounds {
+	if b.clock != nil {
+		return b.At(b.clock())
+	}
+
+	return b.At(time.Now())
+}
+
+func (b scheduledBounds) String() string {
+	return b.current().String()
+}
+
+func (b scheduledBounds) ViolationHint() string {
+	return b.current().ViolationHint()
+}
+
+func (b scheduledBounds) ToNagiosRange() string {
+	return b.current().ToNagiosRange()
+}
+
+func (b scheduledBounds) Match(value float64) bool {
+	return b.current().Match(value)
+}
+
+func (b scheduledBounds) IsInverted() bool {
+	return b.current().IsInverted()
+}
+
+func (b scheduledBounds) Lower() optional.Float64 {
+	return b.current().Lower()
+}
+
+func (b scheduledBounds) Upper() optional.Float64 {
+	return b.current().Upper()
+}
@@ -0,0 +1,77 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestScheduledBounds_At(t *testing.T) {
+	// given
+	dayBounds := NewBounds(UpperBound(10))
+	nightBounds := NewBounds(UpperBound(50))
+	weekendBounds := NewBounds(UpperBound(5))
+	nightWindow, _ := ParseWeeklyWindow("22:00-06:00", time.UTC)
+	weekendWindow, _ := ParseWeeklyWindow("Sat,Sun", time.UTC)
+	bounds := NewScheduledBounds(dayBounds, BoundsDuring(nightWindow, nightBounds), BoundsDuring(weekendWindow, weekendBounds))
+
+	// then
+	assert.Equal(t, dayBounds, bounds.At(time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, nightBounds, bounds.At(time.Date(2019, 6, 21, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, nightBounds, bounds.At(time.Date(2019, 6, 22, 2, 0, 0, 0, time.UTC)))
+	assert.Equal(t, weekendBounds, bounds.At(time.Date(2019, 6, 22, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestScheduledBounds_Bounds(t *testing.T) {
+	// given
+	defaultBounds, _ := NewBoundsFromNagiosRange("@10:20")
+	bounds := NewScheduledBounds(defaultBounds)
+
+	// then
+	assert.Equal(t, defaultBounds.String(), bounds.String())
+	assert.Equal(t, defaultBounds.ViolationHint(), bounds.ViolationHint())
+	assert.Equal(t, defaultBounds.ToNagiosRange(), bounds.ToNagiosRange())
+	assert.Equal(t, defaultBounds.Match(15), bounds.Match(15))
+	assert.Equal(t, defaultBounds.IsInverted(), bounds.IsInverted())
+	assert.Equal(t, defaultBounds.Lower(), bounds.Lower())
+	assert.Equal(t, defaultBounds.Upper(), bounds.Upper())
+}
+
+func TestScheduledBounds_Clock(t *testing.T) {
+	// given
+	now := time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC)
+	nightWindow, _ := ParseWeeklyWindow("22:00-06:00", time.UTC)
+	bounds := NewScheduledBounds(NewBounds(UpperBound(10)), BoundsDuring(nightWindow, NewBounds(UpperBound(50))),
+		ScheduledBoundsClock(func() time.Time { return now }))
+
+	// when
+	dayMatch := bounds.Match(20)
+	dayRange := bounds.ToNagiosRange()
+	now = now.Add(11 * time.Hour)
+	nightMatch := bounds.Match(20)
+	nightRange := bounds.ToNagiosRange()
+
+	// then
+	assert.False(t, dayMatch)
+	assert.Equal(t, ":10", dayRange)
+	assert.True(t, nightMatch)
+	assert.Equal(t, ":50", nightRange)
+}
@@ -20,8 +20,9 @@ package nagopher
 
 import (
 	"fmt"
-	"reflect"
+	"runtime/debug"
 	"sort"
+	"time"
 )
 
 // Check collects metrics (results) and performance data, which are being associated to one or more given contexts.
@@ -45,88 +46,345 @@ type Check interface {
 	VerboseSummary() []string
 }
 
+// TimedCheck is a Check which records when its last execution has started and ended. Both times are zero as long as the
+// check has not been executed yet.
+type TimedCheck interface {
+	Check
+
+	ExecutionStart() time.Time
+	ExecutionEnd() time.Time
+}
+
+// DiagnosticCheck is a Check which keeps the stack traces of recovered panics and the errors returned by resources
+// during its last execution, which can be used for troubleshooting failing checks
+type DiagnosticCheck interface {
+	Check
+
+	PanicTraces() []string
+	ResourceErrors() []ResourceError
+}
+
+// CheckOpt is a type alias for functional options used by NewCheck()
+type CheckOpt func(*baseCheck)
+
+// TeardownPolicy decides how a failing Resource.Teardown() affects the check
+type TeardownPolicy int
+
+// Teardown policies, which can be set using CheckTeardownPolicy()
+const (
+	// TeardownFailureState adds an UNKNOWN result for a failing teardown, which changes the state of the check
+	TeardownFailureState TeardownPolicy = iota
+	// TeardownFailureWarning only adds a warning for a failing teardown without changing the state of the check
+	TeardownFailureWarning
+)
+
 type baseCheck struct {
-	name         string
-	meta         map[string]interface{}
-	contexts     map[string]Context
-	resources    map[*Resource]struct{}
-	performances []PerfData
-	results      ResultCollection
-	summarizer   Summarizer
+	name           string
+	meta           map[string]interface{}
+	contexts       map[string]Context
+	resources      []Resource
+	performances   []PerfData
+	results        ResultCollection
+	panicTraces    []string
+	resourceErrors []ResourceError
+	teardownPolicy TeardownPolicy
+	summarizer     Summarizer
+	executionStart time.Time
+	executionEnd   time.Time
+
+	contextRoutes        []contextRoute
+	defaultContext       string
+	unmatchedPolicy      UnmatchedMetricPolicy
+	additionalContexts   map[string][]string
+	transformers         []MetricTransformer
+	undefinedValuePolicy *undefinedValuePolicy
+
+	metricRequirements    []metricRequirement
+	missingMetricState    State
+	unexpectedMetricState State
+	metricNames           []string
 }
 
-// NewCheck instantiates a new Check object with the given name and summarizer
-func NewCheck(name string, summarizer Summarizer) Check {
+var checkTimeFunction = time.Now
+
+// NewCheck instantiates a new Check object with the given name, summarizer and functional options
+func NewCheck(name string, summarizer Summarizer, options ...CheckOpt) Check {
 	check := &baseCheck{
 		name:       name,
 		summarizer: summarizer,
 		meta:       make(map[string]interface{}),
 		contexts:   make(map[string]Context),
-		resources:  make(map[*Resource]struct{}),
 		results:    NewResultCollection(),
 	}
 
+	for _, option := range options {
+		option(check)
+	}
+
 	return check
 }
 
+// CheckTeardownPolicy is a functional option for NewCheck(), which decides whether a failing teardown of a resource
+// changes the state of the check or only adds a warning. By default, an UNKNOWN result gets added.
+func CheckTeardownPolicy(policy TeardownPolicy) CheckOpt {
+	return func(c *baseCheck) {
+		c.teardownPolicy = policy
+	}
+}
+
+// CheckTransformers is a functional option for NewCheck(), which transforms each NumericMetric returned by any resource
+// using the given transformers in order, before the metric gets routed to its context
+func CheckTransformers(transformers ...MetricTransformer) CheckOpt {
+	return func(c *baseCheck) {
+		c.transformers = append(c.transformers, transformers...)
+	}
+}
+
 func (c *baseCheck) Run(warnings WarningCollection) {
+	c.executionStart = checkTimeFunction()
 	c.results = NewResultCollection()
 	c.performances = []PerfData{}
+	c.panicTraces = nil
+	c.resourceErrors = nil
+	c.metricNames = nil
 
-	for resource := range c.resources {
-		err := c.evaluateResource(warnings, *resource)
-		if err != nil {
-			c.results.Add(NewResult(
-				ResultState(StateUnknown()),
-				ResultResource(*resource), ResultHint(err.Error()),
-			))
-		}
+	for _, resource := range c.resources {
+		c.evaluateResource(warnings, resource)
 	}
+	c.evaluateMetricRequirements()
 
 	sort.SliceStable(c.performances, func(a int, b int) bool {
 		return c.performances[a].Metric().Name() < c.performances[b].Metric().Name()
 	})
+	for _, performance := range c.performances {
+		warningThreshold, criticalThreshold := perfDataThresholds(performance)
+		warnDegradedThreshold(warnings, performance.Metric(), warningThreshold)
+		warnDegradedThreshold(warnings, performance.Metric(), criticalThreshold)
+	}
+
+	c.executionEnd = checkTimeFunction()
 }
 
-func (c *baseCheck) evaluateResource(warnings WarningCollection, resource Resource) error {
-	if err := resource.Setup(warnings); err != nil {
-		return err
+func (c *baseCheck) evaluateResource(warnings WarningCollection, resource Resource) {
+	if err := c.runResourcePhase(ResourcePhaseSetup, resource, func() error {
+		return resource.Setup(warnings)
+	}); err != nil {
+		c.addResourceError(err)
+		return
 	}
 
-	metrics, err := resource.Probe(warnings)
-	if err != nil {
+	defer func() {
+		err := c.runResourcePhase(ResourcePhaseTeardown, resource, func() error {
+			return resource.Teardown(warnings)
+		})
+		if err == nil {
+			return
+		}
+
+		if c.teardownPolicy == TeardownFailureWarning {
+			c.resourceErrors = append(c.resourceErrors, err)
+			warnings.Add(NewWarning("nagopher: %s", c.describeResourceError(err)))
+			return
+		}
+		c.addResourceError(err)
+	}()
+
+	var metrics []Metric
+	if err := c.runResourcePhase(ResourcePhaseProbe, resource, func() (err error) {
+		metrics, err = resource.Probe(warnings)
+		if err == nil && len(metrics) == 0 {
+			name, _ := resourceName(resource)
+			err = fmt.Errorf("nagopher: resource [%s] did not return any metrics", name)
+		}
 		return err
+	}); err != nil {
+		c.addResourceError(err)
+		return
+	}
+
+	if err := c.runResourcePhase(ResourcePhaseEvaluate, resource, func() error {
+		return c.evaluateMetrics(warnings, metrics, resource)
+	}); err != nil {
+		c.addResourceError(err)
+	}
+}
+
+func (c *baseCheck) runResourcePhase(phase ResourcePhase, resource Resource, handler func() error) (err ResourceError) {
+	defer func() {
+		if value := recover(); value != nil {
+			name, _ := resourceName(resource)
+			c.recordPanic(fmt.Sprintf("resource [%s] during %s", name, phase), value)
+			err = newResourceError(phase, resource, fmt.Errorf("nagopher: resource panicked with [%v]", value))
+		}
+	}()
+
+	if cause := handler(); cause != nil {
+		return newResourceError(phase, resource, cause)
 	}
 
-	if len(metrics) == 0 {
-		return fmt.Errorf("nagopher: resource [%s] did not return any metrics", reflect.TypeOf(resource))
+	return nil
+}
+
+func (c *baseCheck) addResourceError(err ResourceError) {
+	c.resourceErrors = append(c.resourceErrors, err)
+	c.results.Add(NewResult(
+		ResultState(StateUnknown()),
+		ResultResource(err.Resource()), ResultHint(c.describeResourceError(err)),
+	))
+}
+
+func (c *baseCheck) describeResourceError(err ResourceError) string {
+	if name, ok := resourceName(err.Resource()); ok {
+		return fmt.Sprintf("%s (resource [%s])", err.Error(), name)
 	}
 
+	return err.Error()
+}
+
+func (c *baseCheck) evaluateMetrics(warnings WarningCollection, metrics []Metric, resource Resource) error {
 	for _, metric := range metrics {
-		context, ok := c.contexts[metric.ContextName()]
+		transformedMetric, err := transformMetric(metric, c.transformers)
+		if err != nil {
+			c.addTransformationError(metric, resource, err)
+			continue
+		}
+		metric = transformedMetric
+		c.metricNames = append(c.metricNames, metric.Name())
+
+		context, ok := c.resolveContext(metric)
+		if !ok {
+			c.handleUnmatchedMetric(warnings, metric, resource)
+			continue
+		}
+
+		additionalContexts, ok := c.additionalContexts[context.Name()]
 		if !ok {
-			return fmt.Errorf("nagopher: missing context with name [%s]", metric.ContextName())
+			if err := c.evaluateMetric(context, metric, resource, "", true); err != nil {
+				return err
+			}
+			continue
 		}
 
-		result := context.Evaluate(metric, resource)
-		c.results.Add(result)
+		if err := c.evaluateMetric(context, metric, resource, context.Name(), true); err != nil {
+			return err
+		}
 
-		perfData, err := context.Performance(metric, resource)
+		for _, contextName := range additionalContexts {
+			additionalContext, ok := c.findContext(contextName, metric)
+			if !ok {
+				c.addResourceError(newResourceError(ResourcePhaseEvaluate, resource,
+					fmt.Errorf("nagopher: missing context with name [%s] for metric [%s]", contextName, metric.Name())))
+				continue
+			}
+
+			if err := c.evaluateMetric(additionalContext, metric, resource, contextName, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *baseCheck) evaluateMetric(context Context, metric Metric, resource Resource, label string, withPerfData bool) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			c.recordPanic(fmt.Sprintf("context [%s]", context.Name()), value)
+			c.results.Add(NewResult(
+				ResultState(StateUnknown()), ResultLabel(label),
+				ResultMetric(metric), ResultResource(resource),
+				ResultHint(fmt.Sprintf("nagopher: context [%s] panicked with [%v]", context.Name(), value)),
+			))
+			err = nil
+		}
+	}()
+
+	if transformingContext, ok := context.(interface{ metricTransformers() []MetricTransformer }); ok {
+		transformedMetric, err := transformMetric(metric, transformingContext.metricTransformers())
 		if err != nil {
-			return fmt.Errorf("nagopher: collecting performance data failed with [%s]", err.Error())
+			c.addTransformationError(metric, resource, err, ResultContext(context), ResultLabel(label))
+			return nil
 		}
-		if performance, err := perfData.Get(); err == nil {
-			c.performances = append(c.performances, performance)
+		metric = transformedMetric
+	}
+
+	// Contexts resolving ScheduledBounds get a fixed clock, so that the state and the performance data of the metric are
+	// based on the same effective thresholds, even when the evaluation straddles the boundary of a schedule window
+	if clockedContext, ok := context.(interface{ withFixedClock() Context }); ok {
+		context = clockedContext.withFixedClock()
+	}
+
+	if hasUndefinedValue(metric) {
+		if policy := c.resolveUndefinedValuePolicy(context); policy != nil {
+			return c.evaluateUndefinedMetric(policy, context, metric, resource, label, withPerfData)
 		}
 	}
 
-	if err := resource.Teardown(warnings); err != nil {
-		return err
+	result := context.Evaluate(metric, resource)
+	if label != "" {
+		result = withResultLabel(result, label)
+	}
+	c.results.Add(result)
+
+	if !withPerfData {
+		return nil
+	}
+
+	return c.addPerformances(context, metric, resource)
+}
+
+func (c *baseCheck) evaluateUndefinedMetric(policy *undefinedValuePolicy, context Context, metric Metric,
+	resource Resource, label string, withPerfData bool) error {
+	c.results.Add(NewResult(
+		ResultState(policy.state), ResultLabel(label),
+		ResultMetric(metric), ResultContext(context), ResultResource(resource),
+		ResultHint(undefinedValueHint),
+	))
+
+	if !withPerfData || policy.perfData == UndefinedPerfDataSkip {
+		return nil
+	}
+
+	return c.addPerformances(context, metric, resource)
+}
+
+func (c *baseCheck) addTransformationError(metric Metric, resource Resource, err error, options ...ResultOpt) {
+	c.results.Add(NewResult(append([]ResultOpt{
+		ResultState(StateUnknown()), ResultResource(resource),
+		ResultHint(fmt.Sprintf("nagopher: transforming metric [%s] failed with [%s]", metric.Name(), err.Error())),
+	}, options...)...))
+}
+
+func (c *baseCheck) recordPanic(source string, value interface{}) {
+	c.panicTraces = append(c.panicTraces, fmt.Sprintf("%s panicked with [%v]\n%s", source, value, debug.Stack()))
+}
+
+func (c *baseCheck) addPerformances(context Context, metric Metric, resource Resource) error {
+	performances, err := c.collectPerformances(context, metric, resource)
+	if err != nil {
+		return fmt.Errorf("nagopher: collecting performance data failed with [%s]", err.Error())
 	}
+	c.performances = append(c.performances, performances...)
 
 	return nil
 }
 
+func (c *baseCheck) collectPerformances(context Context, metric Metric, resource Resource) ([]PerfData, error) {
+	if multiContext, ok := context.(MultiPerfDataContext); ok {
+		return multiContext.MultiPerformance(metric, resource)
+	}
+
+	perfData, err := context.Performance(metric, resource)
+	if err != nil {
+		return nil, err
+	}
+	if performance, err := perfData.Get(); err == nil {
+		return []PerfData{performance}, nil
+	}
+
+	return nil, nil
+}
+
 func (c *baseCheck) SetMeta(key string, value interface{}) {
 	c.meta[key] = value
 }
@@ -141,13 +399,25 @@ func (c baseCheck) GetMeta(key string, defaultValue interface{}) interface{} {
 
 func (c *baseCheck) AttachResources(resources ...Resource) {
 	for _, resource := range resources {
-		c.resources[&resource] = struct{}{}
+		if !c.hasResource(resource) {
+			c.resources = append(c.resources, resource)
+		}
 	}
 }
 
+func (c *baseCheck) hasResource(resource Resource) bool {
+	for _, attachedResource := range c.resources {
+		if isSameResource(attachedResource, resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c *baseCheck) AttachContexts(contexts ...Context) {
 	for _, context := range contexts {
-		c.contexts[context.Name()] = context
+		c.contexts[context.Name()+contextLabelSelector(context).String()] = context
 	}
 }
 
@@ -155,6 +425,14 @@ func (c baseCheck) Results() ResultCollection {
 	return c.results
 }
 
+func (c baseCheck) PanicTraces() []string {
+	return c.panicTraces
+}
+
+func (c baseCheck) ResourceErrors() []ResourceError {
+	return c.resourceErrors
+}
+
 func (c baseCheck) State() State {
 	state, err := c.results.MostSignificantState().Get()
 	if err == nil {
@@ -184,6 +462,22 @@ func (c baseCheck) Name() string {
 	return c.name
 }
 
+func checkExecutionTimes(check Check) (time.Time, time.Time) {
+	if timedCheck, ok := check.(TimedCheck); ok {
+		return timedCheck.ExecutionStart(), timedCheck.ExecutionEnd()
+	}
+
+	return time.Time{}, time.Time{}
+}
+
+func (c baseCheck) ExecutionStart() time.Time {
+	return c.executionStart
+}
+
+func (c baseCheck) ExecutionEnd() time.Time {
+	return c.executionEnd
+}
+
 func (c baseCheck) PerfData() []PerfData {
 	return c.performances
 }
@@ -198,10 +492,5 @@ func (c baseCheck) Contexts() []Context {
 }
 
 func (c baseCheck) Resources() []Resource {
-	resources := make([]Resource, 0, len(c.resources))
-	for resource := range c.resources {
-		resources = append(resources, *resource)
-	}
-
-	return resources
+	return append([]Resource{}, c.resources...)
 }
@@ -0,0 +1,140 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// UnlimitedMetricCount can be passed as maximum count to CheckRequireMetrics() and CheckRequireMetricsRegexp() to
+// allow any amount of matching metrics
+const UnlimitedMetricCount = -1
+
+type metricRequirement struct {
+	pattern  string
+	match    func(metricName string) bool
+	minCount int
+	maxCount int
+}
+
+// CheckRequireMetric is a functional option for NewCheck(), which requires the resources to return at least one metric
+// with the given name. Missing metrics are reported with the state set by CheckMissingMetricState() after each run.
+func CheckRequireMetric(name string) CheckOpt {
+	return func(c *baseCheck) {
+		c.metricRequirements = append(c.metricRequirements, metricRequirement{
+			pattern:  name,
+			match:    func(metricName string) bool { return metricName == name },
+			minCount: 1,
+			maxCount: UnlimitedMetricCount,
+		})
+	}
+}
+
+// CheckRequireMetrics is a functional option for NewCheck(), which requires the resources to return between minCount
+// and maxCount metrics with a name matching the given glob pattern (as supported by path.Match)
+func CheckRequireMetrics(pattern string, minCount int, maxCount int) CheckOpt {
+	return func(c *baseCheck) {
+		c.metricRequirements = append(c.metricRequirements, metricRequirement{
+			pattern: pattern,
+			match: func(metricName string) bool {
+				matched, err := path.Match(pattern, metricName)
+				return err == nil && matched
+			},
+			minCount: minCount,
+			maxCount: maxCount,
+		})
+	}
+}
+
+// CheckRequireMetricsRegexp is a functional option for NewCheck(), which requires the resources to return between
+// minCount and maxCount metrics with a name matching the given regular expression
+func CheckRequireMetricsRegexp(pattern *regexp.Regexp, minCount int, maxCount int) CheckOpt {
+	return func(c *baseCheck) {
+		c.metricRequirements = append(c.metricRequirements, metricRequirement{
+			pattern:  pattern.String(),
+			match:    pattern.MatchString,
+			minCount: minCount,
+			maxCount: maxCount,
+		})
+	}
+}
+
+// CheckMissingMetricState is a functional option for NewCheck(), which sets the state of the results being added for
+// violated metric requirements. By default, an UNKNOWN result gets added.
+func CheckMissingMetricState(state State) CheckOpt {
+	return func(c *baseCheck) {
+		c.missingMetricState = state
+	}
+}
+
+// CheckUnexpectedMetricState is a functional option for NewCheck(), which adds a result with the given state for each
+// metric not matching any of the metric requirements. Unexpected metrics are not being flagged by default.
+func CheckUnexpectedMetricState(state State) CheckOpt {
+	return func(c *baseCheck) {
+		c.unexpectedMetricState = state
+	}
+}
+
+func (c *baseCheck) evaluateMetricRequirements() {
+	missingMetricState := c.missingMetricState
+	if missingMetricState == nil {
+		missingMetricState = StateUnknown()
+	}
+
+	counts := make([]int, len(c.metricRequirements))
+	for _, metricName := range c.metricNames {
+		expected := false
+		for index, requirement := range c.metricRequirements {
+			if requirement.match(metricName) {
+				counts[index]++
+				expected = true
+			}
+		}
+
+		if !expected && c.unexpectedMetricState != nil {
+			c.results.Add(NewResult(
+				ResultState(c.unexpectedMetricState),
+				ResultHint(fmt.Sprintf("nagopher: unexpected metric [%s]", metricName)),
+			))
+		}
+	}
+
+	for index, requirement := range c.metricRequirements {
+		if hint := requirement.violationHint(counts[index]); hint != "" {
+			c.results.Add(NewResult(ResultState(missingMetricState), ResultHint(hint)))
+		}
+	}
+}
+
+func (r metricRequirement) violationHint(count int) string {
+	if count < r.minCount {
+		if r.minCount == 1 {
+			return fmt.Sprintf("nagopher: missing required metric [%s]", r.pattern)
+		}
+		return fmt.Sprintf("nagopher: expected at least %d metrics matching [%s], got %d", r.minCount, r.pattern, count)
+	}
+
+	if r.maxCount != UnlimitedMetricCount && count > r.maxCount {
+		return fmt.Sprintf("nagopher: expected at most %d metrics matching [%s], got %d", r.maxCount, r.pattern, count)
+	}
+
+	return ""
+}
@@ -0,0 +1,76 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestBaseCheck_MetricRequirements(t *testing.T) {
+	// given
+	check := NewCheck("check", NewSummarizer(),
+		CheckRequireMetric("load"),
+		CheckRequireMetric("uptime"),
+		CheckRequireMetrics("eth*_rx", 3, UnlimitedMetricCount),
+		CheckRequireMetricsRegexp(regexp.MustCompile(`^(temp|fan)$`), 0, 1),
+		CheckDefaultContext("default"),
+	)
+	check.AttachResources(&mockRoutingResource{NewResource()})
+	check.AttachContexts(NewScalarContext("default", nil, nil))
+
+	// when
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateUnknown(), check.State())
+	assert.Equal(t, []string{
+		"unknown: nagopher: missing required metric [uptime]",
+		"unknown: nagopher: expected at least 3 metrics matching [eth*_rx], got 2",
+		"unknown: nagopher: expected at most 1 metrics matching [^(temp|fan)$], got 2",
+	}, check.VerboseSummary())
+	assert.Equal(t, 5, len(check.PerfData()))
+}
+
+func TestBaseCheck_MetricRequirements_States(t *testing.T) {
+	// given
+	check := NewCheck("check", NewSummarizer(),
+		CheckRequireMetrics("eth*", 1, 2),
+		CheckRequireMetric("uptime"),
+		CheckMissingMetricState(StateCritical()),
+		CheckUnexpectedMetricState(StateWarning()),
+		CheckDefaultContext("default"),
+	)
+	check.AttachResources(&mockRoutingResource{NewResource()})
+	check.AttachContexts(NewScalarContext("default", nil, nil))
+
+	// when
+	check.Run(NewWarningCollection())
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateCritical(), check.State())
+	assert.Equal(t, []string{
+		"critical: nagopher: missing required metric [uptime]",
+		"warning: nagopher: unexpected metric [load]",
+		"warning: nagopher: unexpected metric [temp]",
+		"warning: nagopher: unexpected metric [fan]",
+	}, check.VerboseSummary())
+}
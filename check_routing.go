@@ -0,0 +1,162 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// UnmatchedMetricPolicy decides how a metric gets handled, which could not be routed to any context
+type UnmatchedMetricPolicy int
+
+// Unmatched metric policies, which can be set using CheckUnmatchedMetricPolicy(). All policies are applied to each
+// metric individually, so that the remaining metrics of the same resource are still evaluated.
+const (
+	// UnmatchedMetricUnknown adds an UNKNOWN result for each unmatched metric
+	UnmatchedMetricUnknown UnmatchedMetricPolicy = iota
+	// UnmatchedMetricWarning ignores unmatched metrics and only adds a warning
+	UnmatchedMetricWarning
+	// UnmatchedMetricInfo adds an INFO result for each unmatched metric, which describes the metric value
+	UnmatchedMetricInfo
+)
+
+type contextRoute struct {
+	match  func(contextName string) bool
+	target string
+}
+
+// CheckRouteExact is a functional option for NewCheck(), which routes metrics with the given context name to the
+// target context. Routes are only consulted if no context with the context name of a metric has been attached and are
+// evaluated in the same order as the options have been passed.
+func CheckRouteExact(contextName string, target string) CheckOpt {
+	return func(c *baseCheck) {
+		c.contextRoutes = append(c.contextRoutes, contextRoute{
+			match:  func(name string) bool { return name == contextName },
+			target: target,
+		})
+	}
+}
+
+// CheckRouteGlob is a functional option for NewCheck(), which routes metrics with a context name matching the given
+// glob pattern (as supported by path.Match) to the target context, e.g. "nic_*" to "nic"
+func CheckRouteGlob(pattern string, target string) CheckOpt {
+	return func(c *baseCheck) {
+		c.contextRoutes = append(c.contextRoutes, contextRoute{
+			match: func(name string) bool {
+				matched, err := path.Match(pattern, name)
+				return err == nil && matched
+			},
+			target: target,
+		})
+	}
+}
+
+// CheckRouteRegexp is a functional option for NewCheck(), which routes metrics with a context name matching the given
+// regular expression to the target context
+func CheckRouteRegexp(pattern *regexp.Regexp, target string) CheckOpt {
+	return func(c *baseCheck) {
+		c.contextRoutes = append(c.contextRoutes, contextRoute{
+			match:  pattern.MatchString,
+			target: target,
+		})
+	}
+}
+
+// CheckDefaultContext is a functional option for NewCheck(), which routes all metrics to the given context, which
+// neither match a context by name nor any of the routes
+func CheckDefaultContext(target string) CheckOpt {
+	return func(c *baseCheck) {
+		c.defaultContext = target
+	}
+}
+
+// CheckUnmatchedMetricPolicy is a functional option for NewCheck(), which decides how metrics are handled that could
+// not be routed to any context. By default, an UNKNOWN result gets added for each of them.
+func CheckUnmatchedMetricPolicy(policy UnmatchedMetricPolicy) CheckOpt {
+	return func(c *baseCheck) {
+		c.unmatchedPolicy = policy
+	}
+}
+
+// CheckAdditionalContexts is a functional option for NewCheck(), which evaluates all metrics resolved to the primary
+// context additionally with the given contexts. Each context produces its own result, labeled with the name of the
+// context, while performance data is only collected once using the primary context and its thresholds.
+func CheckAdditionalContexts(primary string, contexts ...string) CheckOpt {
+	return func(c *baseCheck) {
+		if c.additionalContexts == nil {
+			c.additionalContexts = make(map[string][]string)
+		}
+		c.additionalContexts[primary] = append(c.additionalContexts[primary], contexts...)
+	}
+}
+
+func (c *baseCheck) resolveContext(metric Metric) (Context, bool) {
+	if context, ok := c.findContext(metric.ContextName(), metric); ok {
+		return context, true
+	}
+
+	for _, route := range c.contextRoutes {
+		if !route.match(metric.ContextName()) {
+			continue
+		}
+
+		if context, ok := c.findContext(route.target, metric); ok {
+			return context, true
+		}
+	}
+
+	if c.defaultContext != "" {
+		return c.findContext(c.defaultContext, metric)
+	}
+
+	return nil, false
+}
+
+func (c *baseCheck) findContext(name string, metric Metric) (Context, bool) {
+	var bestContext Context
+	for _, context := range c.contexts {
+		if context.Name() != name || !metricLabels(metric).Matches(contextLabelSelector(context)) {
+			continue
+		}
+
+		if bestContext == nil || len(contextLabelSelector(context)) > len(contextLabelSelector(bestContext)) {
+			bestContext = context
+		}
+	}
+
+	return bestContext, bestContext != nil
+}
+
+func (c *baseCheck) handleUnmatchedMetric(warnings WarningCollection, metric Metric, resource Resource) {
+	switch c.unmatchedPolicy {
+	case UnmatchedMetricWarning:
+		warnings.Add(NewWarning("nagopher: ignoring metric [%s] without context [%s]", metric.Name(), metric.ContextName()))
+	case UnmatchedMetricInfo:
+		c.results.Add(NewResult(
+			ResultState(StateInfo()),
+			ResultMetric(metric), ResultResource(resource),
+			ResultContext(NewBaseContext(metric.ContextName(), "%<name>s is %<value>s%<unit>s")),
+		))
+	default:
+		c.addResourceError(newResourceError(ResourcePhaseEvaluate, resource,
+			fmt.Errorf("nagopher: missing context with name [%s] for metric [%s]", metric.ContextName(), metric.Name())))
+	}
+}
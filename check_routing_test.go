@@ -0,0 +1,160 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+type mockRoutingResource struct {
+	Resource
+}
+
+type mockMetricsResource struct {
+	Resource
+	metrics []Metric
+}
+
+func TestBaseCheck_ContextRoutes(t *testing.T) {
+	// given
+	nicThreshold, _ := NewBoundsFromNagiosRange("100")
+	sensorThreshold, _ := NewBoundsFromNagiosRange("50")
+	check := NewCheck("check", NewSummarizer(),
+		CheckRouteExact("load", "missing"),
+		CheckRouteGlob("nic_*", "nic"),
+		CheckRouteRegexp(regexp.MustCompile(`^sensor_`), "sensor"),
+		CheckDefaultContext("default"),
+	)
+	check.AttachResources(&mockRoutingResource{NewResource()})
+	check.AttachContexts(
+		NewScalarContext("load", nil, nil),
+		NewScalarContext("nic", &nicThreshold, nil),
+		NewScalarContext("sensor", &sensorThreshold, nil),
+		NewScalarContext("default", nil, nil),
+	)
+
+	// when
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateWarning(), check.State())
+	assert.Equal(t, 5, check.Results().Count())
+	assert.Equal(t, []string{
+		"eth0_rx=120;:100", "eth1_rx=80;:100", "fan=3000", "load=1.5", "temp=60C;:50",
+	}, perfDataStrings(check.PerfData()))
+}
+
+func TestBaseCheck_UnmatchedMetricPolicy(t *testing.T) {
+	// given
+	check1 := NewCheck("check", NewSummarizer())
+	check2 := NewCheck("check", NewSummarizer(), CheckUnmatchedMetricPolicy(UnmatchedMetricWarning))
+	check3 := NewCheck("check", NewSummarizer(), CheckUnmatchedMetricPolicy(UnmatchedMetricInfo))
+	warnings1 := NewWarningCollection()
+	warnings2 := NewWarningCollection()
+	warnings3 := NewWarningCollection()
+
+	for _, check := range []Check{check1, check2, check3} {
+		check.AttachResources(&mockRoutingResource{NewResource()})
+		check.AttachContexts(NewScalarContext("load", nil, nil), NewScalarContext("fan", nil, nil))
+	}
+
+	// when
+	check1.Run(warnings1)
+	check2.Run(warnings2)
+	check3.Run(warnings3)
+
+	// then
+	assert.Equal(t, StateUnknown(), check1.State())
+	assert.Equal(t, 5, check1.Results().Count())
+	assert.Equal(t, 3, len(check1.(DiagnosticCheck).ResourceErrors()))
+	assert.Equal(t, "evaluate: nagopher: missing context with name [nic_eth0] for metric [eth0_rx]", check1.Summary())
+	assert.Equal(t, []string{"fan=3000", "load=1.5"}, perfDataStrings(check1.PerfData()))
+
+	assert.Equal(t, StateOk(), check2.State())
+	assert.Equal(t, 2, check2.Results().Count())
+	assert.Equal(t, []string{
+		"nagopher: ignoring metric [eth0_rx] without context [nic_eth0]",
+		"nagopher: ignoring metric [eth1_rx] without context [nic_eth1]",
+		"nagopher: ignoring metric [temp] without context [sensor_temp]",
+	}, warnings2.GetWarningStrings())
+
+	assert.Equal(t, StateOk(), check3.State())
+	assert.Equal(t, 5, check3.Results().Count())
+	assert.Equal(t, []string{
+		"info: eth0_rx is 120", "info: eth1_rx is 80", "info: temp is 60C",
+	}, check3.VerboseSummary())
+	assert.Equal(t, []string{"fan=3000", "load=1.5"}, perfDataStrings(check3.PerfData()))
+	assert.Empty(t, warnings3.Get())
+}
+
+func TestBaseCheck_AdditionalContexts(t *testing.T) {
+	// given
+	threshold, _ := NewBoundsFromNagiosRange("100")
+	strictThreshold, _ := NewBoundsFromNagiosRange("50")
+	check := NewCheck("check", NewSummarizer(),
+		CheckRouteGlob("*", "absolute"),
+		CheckAdditionalContexts("absolute", "strict", "missing"),
+	)
+	check.AttachResources(&mockMetricsResource{NewResource(), []Metric{MustNewNumericMetric("latency", 80, "ms", nil, "latency")}})
+	check.AttachContexts(
+		NewScalarContext("absolute", &threshold, nil),
+		NewScalarContext("strict", &strictThreshold, nil),
+	)
+
+	// when
+	check.Run(NewWarningCollection())
+	labels := []string{}
+	for _, result := range check.Results().Get() {
+		labels = append(labels, resultLabel(result))
+	}
+
+	// then
+	assert.Equal(t, StateUnknown(), check.State())
+	assert.Equal(t, []string{"", "strict", "absolute"}, labels)
+	assert.Equal(t, []string{
+		"unknown: evaluate: nagopher: missing context with name [missing] for metric [latency]",
+		"warning: strict: latency is 80ms (outside range 0:50)",
+	}, check.VerboseSummary())
+	assert.Equal(t, []string{"latency=80ms;:100"}, perfDataStrings(check.PerfData()))
+}
+
+func (r mockRoutingResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	return []Metric{
+		MustNewNumericMetric("eth0_rx", 120, "", nil, "nic_eth0"),
+		MustNewNumericMetric("eth1_rx", 80, "", nil, "nic_eth1"),
+		MustNewNumericMetric("load", 1.5, "", nil, "load"),
+		MustNewNumericMetric("temp", 60, "C", nil, "sensor_temp"),
+		MustNewNumericMetric("fan", 3000, "", nil, "fan"),
+	}, nil
+}
+
+func (r mockMetricsResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	return r.metrics, nil
+}
+
+func perfDataStrings(perfData []PerfData) []string {
+	results := make([]string, 0, len(perfData))
+	for _, value := range perfData {
+		results = append(results, value.ToNagiosPerfData())
+	}
+
+	return results
+}
@@ -19,8 +19,11 @@
 package nagopher
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestBaseCheck_GetSetMeta(t *testing.T) {
@@ -55,6 +58,43 @@ func TestBaseCheck_AttachContexts(t *testing.T) {
 	assert.Contains(t, check.Contexts(), context2)
 }
 
+func TestBaseCheck_AttachContexts_LabelSelector(t *testing.T) {
+	// given
+	context1 := NewStringInfoContext("context")
+	context2 := NewStringInfoContext("context", ContextLabelSelector(Labels{"mount": "/var"}))
+	context3 := NewStringInfoContext("context", ContextLabelSelector(Labels{"mount": "/var"}))
+	check := NewCheck("check", NewSummarizer())
+
+	// when
+	check.AttachContexts(context1, context2, context3)
+
+	// then
+	assert.Equal(t, 2, len(check.Contexts()))
+	assert.Contains(t, check.Contexts(), context1)
+	assert.Contains(t, check.Contexts(), context3)
+}
+
+func TestBaseCheck_Run_LabelSelector(t *testing.T) {
+	// given
+	varThreshold, _ := NewBoundsFromNagiosRange("50")
+	defaultThreshold, _ := NewBoundsFromNagiosRange("90")
+	check := NewCheck("disk", NewSummarizer())
+	check.AttachResources(newMockLabeledResource())
+	check.AttachContexts(
+		NewScalarContext("disk", nil, &defaultThreshold),
+		NewScalarContext("disk", nil, &varThreshold, ContextLabelSelector(Labels{"mount": "/var"})),
+	)
+
+	// when
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateCritical(), check.State())
+	assert.Equal(t, "/var_used is 60% (outside range 0:50)", check.Summary())
+	assert.Equal(t, "'/tmp_used'=60%;;:90", check.PerfData()[0].ToNagiosPerfData())
+	assert.Equal(t, "'/var_used'=60%;;:50", check.PerfData()[1].ToNagiosPerfData())
+}
+
 func TestBaseCheck_AttachResources(t *testing.T) {
 	// given
 	resource1 := NewResource()
@@ -71,6 +111,58 @@ func TestBaseCheck_AttachResources(t *testing.T) {
 	assert.Contains(t, check.Resources(), resource2)
 }
 
+func TestBaseCheck_AttachResources_Order(t *testing.T) {
+	// given
+	resource1 := NewNamedResource("first")
+	resource2 := NewNamedResource("second")
+	resource3 := NewNamedResource("third")
+	check := NewCheck("check", NewSummarizer())
+
+	// when
+	check.AttachResources(resource2, resource1)
+	check.AttachResources(resource1, resource3, resource2)
+
+	// then
+	assert.Equal(t, []Resource{resource2, resource1, resource3}, check.Resources())
+}
+
+func TestBaseCheck_AttachResources_Uncomparable(t *testing.T) {
+	// given
+	check := NewCheck("check", NewSummarizer())
+	wrappingResource := mockWrappingResource{Resource: mockUncomparableResource{Resource: NewResource()}}
+
+	// when
+	attach := func() { check.AttachResources(wrappingResource, wrappingResource) }
+
+	// then
+	assert.NotPanics(t, attach)
+}
+
+func TestBaseCheck_Run_NamedResource(t *testing.T) {
+	// given
+	warningThreshold, _ := NewBoundsFromNagiosRange("10")
+	check := NewCheck("check", NewSummarizer())
+	check.AttachResources(
+		&mockNamedResource{NamedResource: NewNamedResource("primary"), value: 42},
+		&mockNamedResource{NamedResource: NewNamedResource("replica")},
+	)
+	check.AttachContexts(NewScalarContext("usage", &warningThreshold, nil))
+
+	// when
+	check.Run(NewWarningCollection())
+	report := newCheckReport(check, NewCheckResult(check.State().ExitCode(), ""))
+
+	// then
+	assert.Equal(t, StateUnknown(), check.State())
+	assert.Equal(t, "probe: connection refused (resource [replica])", check.Summary())
+	assert.Equal(t, []string{
+		"unknown: probe: connection refused (resource [replica])",
+		"warning: usage is 42 (outside range 0:10) (resource [primary])",
+	}, check.VerboseSummary())
+	assert.Equal(t, "replica", report.Results[0].Resource)
+	assert.Equal(t, "primary", report.Results[1].Resource)
+}
+
 func TestBaseCheck_State(t *testing.T) {
 	// given
 	check1 := NewCheck("check 1", NewSummarizer())
@@ -125,3 +217,187 @@ func TestBaseCheck_VerboseSummary(t *testing.T) {
 	// then
 	assert.Equal(t, summarizer.Verbose(check), verboseSummary)
 }
+
+type mockLabeledResource struct {
+	Resource
+}
+
+func newMockLabeledResource() Resource {
+	return &mockLabeledResource{
+		Resource: NewResource(),
+	}
+}
+
+func (r mockLabeledResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	return []Metric{
+		MustNewNumericMetric("{mount}_used", 60, "%", nil, "disk", MetricLabel("mount", "/var")),
+		MustNewNumericMetric("{mount}_used", 60, "%", nil, "disk", MetricLabel("mount", "/tmp")),
+	}, nil
+}
+
+func TestBaseCheck_ExecutionTime(t *testing.T) {
+	// given
+	start := time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC)
+	defer mockCheckTimeFunction(start, time.Second)()
+	check := NewCheck("check", NewSummarizer())
+
+	// when
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Implements(t, (*TimedCheck)(nil), check)
+	assert.Equal(t, start, check.(TimedCheck).ExecutionStart())
+	assert.Equal(t, start.Add(time.Second), check.(TimedCheck).ExecutionEnd())
+}
+
+type mockNamedResource struct {
+	NamedResource
+	value float64
+}
+
+func (r mockNamedResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	if r.value == 0 {
+		return nil, errors.New("connection refused")
+	}
+
+	return []Metric{MustNewNumericMetric("usage", r.value, "", nil, "")}, nil
+}
+
+func TestBaseCheck_Run_Lifecycle(t *testing.T) {
+	testCases := []struct {
+		resource       *mockLifecycleResource
+		expectedCalls  []ResourcePhase
+		expectedPhases []ResourcePhase
+	}{
+		{&mockLifecycleResource{},
+			[]ResourcePhase{ResourcePhaseSetup, ResourcePhaseProbe, ResourcePhaseTeardown}, nil},
+		{&mockLifecycleResource{failingPhase: ResourcePhaseSetup},
+			[]ResourcePhase{ResourcePhaseSetup}, []ResourcePhase{ResourcePhaseSetup}},
+		{&mockLifecycleResource{failingPhase: ResourcePhaseProbe},
+			[]ResourcePhase{ResourcePhaseSetup, ResourcePhaseProbe, ResourcePhaseTeardown},
+			[]ResourcePhase{ResourcePhaseProbe}},
+		{&mockLifecycleResource{failingPhase: ResourcePhaseProbe, panicking: true},
+			[]ResourcePhase{ResourcePhaseSetup, ResourcePhaseProbe, ResourcePhaseTeardown},
+			[]ResourcePhase{ResourcePhaseProbe}},
+		{&mockLifecycleResource{failingPhase: ResourcePhaseEvaluate},
+			[]ResourcePhase{ResourcePhaseSetup, ResourcePhaseProbe, ResourcePhaseTeardown},
+			[]ResourcePhase{ResourcePhaseEvaluate}},
+		{&mockLifecycleResource{failingPhase: ResourcePhaseProbe, failingTeardown: true},
+			[]ResourcePhase{ResourcePhaseSetup, ResourcePhaseProbe, ResourcePhaseTeardown},
+			[]ResourcePhase{ResourcePhaseProbe, ResourcePhaseTeardown}},
+	}
+
+	for _, testCase := range testCases {
+		// given
+		check := NewCheck("check", NewSummarizer())
+		check.AttachResources(testCase.resource)
+		check.AttachContexts(NewScalarContext("usage", nil, nil))
+
+		// when
+		check.Run(NewWarningCollection())
+
+		var phases []ResourcePhase
+		for _, err := range check.(DiagnosticCheck).ResourceErrors() {
+			assert.Equal(t, testCase.resource, err.Resource())
+			assert.Error(t, err.Cause())
+			phases = append(phases, err.Phase())
+		}
+
+		// then
+		assert.Equal(t, testCase.expectedCalls, testCase.resource.calls)
+		assert.Equal(t, testCase.expectedPhases, phases)
+		assert.Equal(t, len(testCase.expectedPhases), check.Results().Count()-len(check.PerfData()))
+	}
+}
+
+func TestBaseCheck_Run_TeardownPolicy(t *testing.T) {
+	// given
+	check1 := NewCheck("check", NewSummarizer())
+	check2 := NewCheck("check", NewSummarizer(), CheckTeardownPolicy(TeardownFailureWarning))
+	warnings1 := NewWarningCollection()
+	warnings2 := NewWarningCollection()
+
+	for _, check := range []Check{check1, check2} {
+		check.AttachResources(&mockLifecycleResource{failingTeardown: true})
+		check.AttachContexts(NewScalarContext("usage", nil, nil))
+	}
+
+	// when
+	check1.Run(warnings1)
+	check2.Run(warnings2)
+
+	// then
+	assert.Equal(t, StateUnknown(), check1.State())
+	assert.Equal(t, "teardown: artificial error in teardown", check1.Summary())
+	assert.Empty(t, warnings1.Get())
+
+	assert.Equal(t, StateOk(), check2.State())
+	assert.Equal(t, []string{"nagopher: teardown: artificial error in teardown"}, warnings2.GetWarningStrings())
+	assert.Equal(t, 1, len(check2.(DiagnosticCheck).ResourceErrors()))
+}
+
+type mockLifecycleResource struct {
+	failingPhase    ResourcePhase
+	failingTeardown bool
+	panicking       bool
+	calls           []ResourcePhase
+}
+
+func (r *mockLifecycleResource) fail(phase ResourcePhase) error {
+	r.calls = append(r.calls, phase)
+	if r.failingPhase != phase && (phase != ResourcePhaseTeardown || !r.failingTeardown) {
+		return nil
+	}
+
+	if r.panicking {
+		panic("artificial panic in " + string(phase))
+	}
+
+	return fmt.Errorf("artificial error in %s", phase)
+}
+
+func (r *mockLifecycleResource) Setup(warnings WarningCollection) error {
+	return r.fail(ResourcePhaseSetup)
+}
+
+func (r *mockLifecycleResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	if err := r.fail(ResourcePhaseProbe); err != nil {
+		return nil, err
+	}
+
+	if r.failingPhase == ResourcePhaseEvaluate {
+		return []Metric{MustNewNumericMetric("usage", 42, "", nil, "missing")}, nil
+	}
+
+	return []Metric{MustNewNumericMetric("usage", 42, "", nil, "")}, nil
+}
+
+func (r *mockLifecycleResource) Teardown(warnings WarningCollection) error {
+	return r.fail(ResourcePhaseTeardown)
+}
+
+func TestBaseCheck_Transformers(t *testing.T) {
+	// given
+	threshold, _ := NewBoundsFromNagiosRange("80")
+	valueRange, _ := NewBoundsFromNagiosRange("0:1")
+	check := NewCheck("check", NewSummarizer(), CheckTransformers(NewUnitTransformer("%")))
+	check.AttachResources(&mockMetricsResource{NewResource(), []Metric{
+		MustNewNumericMetric("free", 0.1, "", &valueRange, "usage"),
+		MustNewNumericMetric("size", 1024, "B", nil, "size"),
+	}})
+	check.AttachContexts(
+		NewScalarContext("usage", &threshold, nil, ContextTransformers(NewInvertTransformer(), NewRenameTransformer("used"))),
+		NewScalarContext("size", nil, nil),
+	)
+
+	// when
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateUnknown(), check.State())
+	assert.Equal(t, []string{
+		"unknown: nagopher: transforming metric [size] failed with [nagopher: can not convert metric [size] from unit [B] to [%]]",
+		"warning: used is 90% (outside range 0:80) (original value [0.1])",
+	}, check.VerboseSummary())
+	assert.Equal(t, []string{"used=90%;:80;;;100"}, perfDataStrings(check.PerfData()))
+}
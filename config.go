@@ -0,0 +1,920 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResourceFactory creates a new Resource based on the parameters of a resource definition within a config file
+type ResourceFactory func(params ConfigParams) (Resource, error)
+
+// ContextFactory creates a new Context based on the parameters of a context definition within a config file. The
+// options contain the format, label selector and threshold map, which are handled by the config loader itself.
+type ContextFactory func(name string, params ConfigParams, options ...ContextOpt) (Context, error)
+
+// SummarizerFactory creates a new Summarizer based on the parameters of the summarizer definition within a config file
+type SummarizerFactory func(params ConfigParams) (Summarizer, error)
+
+// ConfigRegistry holds all Resource, Context and Summarizer types, which can be referenced by their type name within
+// declarative check definitions. Applications register their own resource types, while all contexts provided by this
+// library are registered by default.
+type ConfigRegistry interface {
+	RegisterResource(typeName string, factory ResourceFactory)
+	RegisterContext(typeName string, factory ContextFactory)
+	RegisterSummarizer(typeName string, factory SummarizerFactory)
+
+	LoadCheckConfig(path string) (CheckConfig, error)
+	ParseCheckConfig(fileName string, data []byte) (CheckConfig, error)
+}
+
+// CheckConfig represents a validated check definition, which was loaded from a YAML or JSON file. Every call of
+// NewCheck() returns a new Check instance, while Runtime() returns a Runtime matching the configured output mode. As
+// resource factories are only called by NewCheck(), errors within resource parameters are reported there as well.
+type CheckConfig interface {
+	Name() string
+	NewCheck() (Check, error)
+	Runtime() Runtime
+}
+
+// ConfigParams provides typed access to the parameters of a definition within a config file. All errors contain the
+// file name, line and field path of the offending value. Parameters which were never accessed by a factory are
+// reported as unknown fields.
+type ConfigParams interface {
+	Has(key string) bool
+	String(key string, defaultValue string) (string, error)
+	Float(key string, defaultValue float64) (float64, error)
+	Bool(key string, defaultValue bool) (bool, error)
+	Duration(key string, defaultValue time.Duration) (time.Duration, error)
+	Bounds(key string) (*Bounds, error)
+	State(key string, defaultValue State) (State, error)
+	Strings(key string) ([]string, error)
+	Labels(key string) (Labels, error)
+	Map(key string) (ConfigParams, error)
+	List(key string) ([]ConfigParams, error)
+	Errorf(key string, format string, values ...interface{}) error
+}
+
+type configRegistry struct {
+	mutex       sync.RWMutex
+	resources   map[string]ResourceFactory
+	contexts    map[string]ContextFactory
+	summarizers map[string]SummarizerFactory
+}
+
+type checkConfig struct {
+	registry *configRegistry
+	fileName string
+	root     *yaml.Node
+	name     string
+	output   string
+	verbose  bool
+}
+
+type configParams struct {
+	fileName string
+	path     string
+	node     *yaml.Node
+	used     map[string]bool
+	children []*configParams
+	opaque   bool
+}
+
+type configSummarizer struct {
+	Summarizer
+	okMessage    string
+	emptyMessage string
+}
+
+const (
+	configOutputNagios  = "nagios"
+	configOutputCheckmk = "checkmk"
+)
+
+var configStates = map[string]State{
+	"ok":       StateOk(),
+	"info":     StateInfo(),
+	"warning":  StateWarning(),
+	"critical": StateCritical(),
+	"unknown":  StateUnknown(),
+}
+
+// NewConfigRegistry instantiates a new ConfigRegistry, which already contains the built-in context types "scalar",
+// "string-info", "string-match", "histogram", "enum", "version", "set" and "duration" as well as the summarizer type "default"
+func NewConfigRegistry() ConfigRegistry {
+	registry := &configRegistry{
+		resources:   make(map[string]ResourceFactory),
+		contexts:    make(map[string]ContextFactory),
+		summarizers: make(map[string]SummarizerFactory),
+	}
+
+	registry.RegisterContext("scalar", newScalarContextFromConfig)
+	registry.RegisterContext("string-info", newStringInfoContextFromConfig)
+	registry.RegisterContext("string-match", newStringMatchContextFromConfig)
+	registry.RegisterContext("histogram", newHistogramContextFromConfig)
+	registry.RegisterContext("enum", newEnumContextFromConfig)
+	registry.RegisterContext("version", newVersionContextFromConfig)
+	registry.RegisterContext("set", newSetContextFromConfig)
+	registry.RegisterContext("duration", newDurationContextFromConfig)
+	registry.RegisterSummarizer("default", newSummarizerFromConfig)
+
+	return registry
+}
+
+func (r *configRegistry) RegisterResource(typeName string, factory ResourceFactory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.resources[typeName] = factory
+}
+
+func (r *configRegistry) RegisterContext(typeName string, factory ContextFactory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.contexts[typeName] = factory
+}
+
+func (r *configRegistry) RegisterSummarizer(typeName string, factory SummarizerFactory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.summarizers[typeName] = factory
+}
+
+// snapshot copies all registered factories, so that they can be called without holding the lock of the registry. This
+// allows factories to register further types without deadlocking.
+func (r *configRegistry) snapshot() *configRegistry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	snapshot := &configRegistry{
+		resources:   make(map[string]ResourceFactory, len(r.resources)),
+		contexts:    make(map[string]ContextFactory, len(r.contexts)),
+		summarizers: make(map[string]SummarizerFactory, len(r.summarizers)),
+	}
+	for typeName, factory := range r.resources {
+		snapshot.resources[typeName] = factory
+	}
+	for typeName, factory := range r.contexts {
+		snapshot.contexts[typeName] = factory
+	}
+	for typeName, factory := range r.summarizers {
+		snapshot.summarizers[typeName] = factory
+	}
+
+	return snapshot
+}
+
+func (r *configRegistry) LoadCheckConfig(path string) (CheckConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("nagopher: could not read config file (%s)", err.Error())
+	}
+
+	return r.ParseCheckConfig(path, data)
+}
+
+func (r *configRegistry) ParseCheckConfig(fileName string, data []byte) (CheckConfig, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("nagopher: %s: %s", fileName, err.Error())
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("nagopher: %s: config must contain a mapping of check settings", fileName)
+	}
+
+	config := &checkConfig{
+		registry: r,
+		fileName: fileName,
+		root:     document.Content[0],
+	}
+
+	root := newConfigParams(fileName, "", config.root)
+	var err error
+	if config.name, err = root.String("name", ""); err != nil {
+		return nil, err
+	}
+	if config.verbose, err = root.Bool("verbose", false); err != nil {
+		return nil, err
+	}
+	if config.output, err = root.String("output", configOutputNagios); err != nil {
+		return nil, err
+	}
+	if config.output != configOutputNagios && config.output != configOutputCheckmk {
+		return nil, root.Errorf("output", "unknown output mode [%s], expected one of [%s, %s]",
+			config.output, configOutputNagios, configOutputCheckmk)
+	}
+
+	if _, err := config.build(r.snapshot(), false); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c checkConfig) Name() string {
+	return c.name
+}
+
+func (c checkConfig) Runtime() Runtime {
+	if c.output == configOutputCheckmk {
+		return NewCheckmkRuntime(CheckmkVerboseOutput(c.verbose))
+	}
+
+	return NewRuntime(c.verbose)
+}
+
+func (c checkConfig) NewCheck() (Check, error) {
+	return c.build(c.registry.snapshot(), true)
+}
+
+// build creates a new check based on the config by using the factories of the given registry. Without buildResources,
+// only the types of all resources are validated instead of calling their factories, as creating a resource might have
+// side effects like opening connections. Errors within the parameters of a resource are then reported by NewCheck().
+func (c checkConfig) build(registry *configRegistry, buildResources bool) (Check, error) {
+	root := newConfigParams(c.fileName, "", c.root)
+	root.used["name"], root.used["output"], root.used["verbose"] = true, true, true
+
+	summarizer, err := c.buildSummarizer(registry, root)
+	if err != nil {
+		return nil, err
+	}
+
+	check := NewCheck(c.name, summarizer)
+
+	resourceParams, err := root.List("resources")
+	if err != nil {
+		return nil, err
+	}
+	if len(resourceParams) == 0 {
+		return nil, root.Errorf("resources", "at least one resource must be defined")
+	}
+
+	for _, params := range resourceParams {
+		factory, err := c.resolveResource(registry, params.(*configParams))
+		if err != nil {
+			return nil, err
+		}
+		if !buildResources {
+			params.(*configParams).opaque = true
+			continue
+		}
+
+		resource, err := factory(params)
+		if err != nil {
+			return nil, err
+		}
+		check.AttachResources(resource)
+	}
+
+	contextParams, err := root.List("contexts")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, params := range contextParams {
+		context, err := c.buildContext(registry, params.(*configParams))
+		if err != nil {
+			return nil, err
+		}
+		check.AttachContexts(context)
+	}
+
+	if err := root.checkUnknownFields(); err != nil {
+		return nil, err
+	}
+
+	return check, nil
+}
+
+func (c checkConfig) buildSummarizer(registry *configRegistry, root *configParams) (Summarizer, error) {
+	params, err := root.Map("summarizer")
+	if err != nil || params == nil {
+		return NewSummarizer(), err
+	}
+
+	typeName, err := params.String("type", "default")
+	if err != nil {
+		return nil, err
+	}
+
+	factory, ok := registry.summarizers[typeName]
+	if !ok {
+		return nil, params.Errorf("type", "unknown summarizer type [%s], expected one of [%s]",
+			typeName, strings.Join(sortedConfigTypes(registry.summarizers), ", "))
+	}
+
+	summarizer, err := factory(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizer, nil
+}
+
+func (c checkConfig) resolveResource(registry *configRegistry, params *configParams) (ResourceFactory, error) {
+	typeName, err := params.requiredString("type")
+	if err != nil {
+		return nil, err
+	}
+
+	factory, ok := registry.resources[typeName]
+	if !ok {
+		return nil, params.Errorf("type", "unknown resource type [%s], expected one of [%s]",
+			typeName, strings.Join(sortedConfigTypes(registry.resources), ", "))
+	}
+
+	return factory, nil
+}
+
+func (c checkConfig) buildContext(registry *configRegistry, params *configParams) (Context, error) {
+	typeName, err := params.requiredString("type")
+	if err != nil {
+		return nil, err
+	}
+	name, err := params.requiredString("name")
+	if err != nil {
+		return nil, err
+	}
+
+	factory, ok := registry.contexts[typeName]
+	if !ok {
+		return nil, params.Errorf("type", "unknown context type [%s], expected one of [%s]",
+			typeName, strings.Join(sortedConfigTypes(registry.contexts), ", "))
+	}
+
+	var options []ContextOpt
+	if params.Has("format") {
+		format, err := params.String("format", "")
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, ContextFormat(format))
+	}
+
+	labels, err := params.Labels("labels")
+	if err != nil {
+		return nil, err
+	} else if labels != nil {
+		options = append(options, ContextLabelSelector(labels))
+	}
+
+	if params.Has("thresholds") {
+		specifier, err := params.String("thresholds", "")
+		if err != nil {
+			return nil, err
+		}
+
+		thresholdMap, err := ParseThresholdMap(specifier)
+		if err != nil {
+			return nil, params.Errorf("thresholds", "%s", err.Error())
+		}
+		options = append(options, ContextThresholdMap(thresholdMap))
+	}
+
+	return factory(name, params, options...)
+}
+
+func newConfigParams(fileName string, path string, node *yaml.Node) *configParams {
+	return &configParams{
+		fileName: fileName,
+		path:     path,
+		node:     node,
+		used:     make(map[string]bool),
+	}
+}
+
+func (p *configParams) lookup(key string) *yaml.Node {
+	p.used[key] = true
+	for index := 0; index+1 < len(p.node.Content); index += 2 {
+		if p.node.Content[index].Value == key {
+			return p.node.Content[index+1]
+		}
+	}
+
+	return nil
+}
+
+func (p *configParams) scalar(key string) (*yaml.Node, error) {
+	node := p.lookup(key)
+	if node != nil && node.Kind != yaml.ScalarNode {
+		return nil, p.Errorf(key, "expected a single value")
+	}
+
+	return node, nil
+}
+
+func (p *configParams) requiredString(key string) (string, error) {
+	if !p.Has(key) {
+		return "", p.Errorf(key, "field is required")
+	}
+
+	return p.String(key, "")
+}
+
+func (p *configParams) Has(key string) bool {
+	for index := 0; index+1 < len(p.node.Content); index += 2 {
+		if p.node.Content[index].Value == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *configParams) String(key string, defaultValue string) (string, error) {
+	node, err := p.scalar(key)
+	if err != nil || node == nil {
+		return defaultValue, err
+	}
+
+	return node.Value, nil
+}
+
+func (p *configParams) Float(key string, defaultValue float64) (float64, error) {
+	node, err := p.scalar(key)
+	if err != nil || node == nil {
+		return defaultValue, err
+	}
+
+	value, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return defaultValue, p.Errorf(key, "invalid number [%s]", node.Value)
+	}
+
+	return value, nil
+}
+
+func (p *configParams) Bool(key string, defaultValue bool) (bool, error) {
+	node, err := p.scalar(key)
+	if err != nil || node == nil {
+		return defaultValue, err
+	}
+
+	var value bool
+	if err := node.Decode(&value); err != nil {
+		return defaultValue, p.Errorf(key, "invalid boolean [%s]", node.Value)
+	}
+
+	return value, nil
+}
+
+func (p *configParams) Duration(key string, defaultValue time.Duration) (time.Duration, error) {
+	node, err := p.scalar(key)
+	if err != nil || node == nil {
+		return defaultValue, err
+	}
+
+	value, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return defaultValue, p.Errorf(key, "invalid duration [%s]", node.Value)
+	}
+
+	return value, nil
+}
+
+func (p *configParams) Bounds(key string) (*Bounds, error) {
+	node, err := p.scalar(key)
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	if strings.ContainsAny(node.Value, ",()") {
+		rangeList, err := NewRangeListFromNagiosRanges(node.Value)
+		if err != nil {
+			return nil, p.Errorf(key, "invalid range list [%s]", node.Value)
+		}
+
+		bounds := Bounds(rangeList)
+		return &bounds, nil
+	}
+
+	bounds, err := NewBoundsFromNagiosRange(node.Value)
+	if err != nil {
+		return nil, p.Errorf(key, "invalid nagios range [%s]", node.Value)
+	}
+
+	return &bounds, nil
+}
+
+func (p *configParams) State(key string, defaultValue State) (State, error) {
+	node, err := p.scalar(key)
+	if err != nil || node == nil {
+		return defaultValue, err
+	}
+
+	state, ok := configStates[strings.ToLower(node.Value)]
+	if !ok {
+		return defaultValue, p.Errorf(key, "unknown state [%s], expected one of [ok, info, warning, critical, unknown]",
+			node.Value)
+	}
+
+	return state, nil
+}
+
+func (p *configParams) Strings(key string) ([]string, error) {
+	node := p.lookup(key)
+	if node == nil {
+		return nil, nil
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}, nil
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for index, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, p.errorAt(item, fmt.Sprintf("%s[%d]", p.fieldPath(key), index), "expected a single value")
+			}
+			values = append(values, item.Value)
+		}
+		return values, nil
+	}
+
+	return nil, p.Errorf(key, "expected a list of values")
+}
+
+func (p *configParams) Labels(key string) (Labels, error) {
+	node := p.lookup(key)
+	if node == nil {
+		return nil, nil
+	}
+
+	labels := Labels{}
+	if err := node.Decode(&labels); err != nil {
+		return nil, p.Errorf(key, "expected a mapping of label names to values")
+	}
+
+	return labels, nil
+}
+
+func (p *configParams) Map(key string) (ConfigParams, error) {
+	node := p.lookup(key)
+	if node == nil {
+		return nil, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, p.Errorf(key, "expected a mapping")
+	}
+
+	child := newConfigParams(p.fileName, p.fieldPath(key), node)
+	p.children = append(p.children, child)
+
+	return child, nil
+}
+
+func (p *configParams) List(key string) ([]ConfigParams, error) {
+	node := p.lookup(key)
+	if node == nil {
+		return nil, nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil, p.Errorf(key, "expected a list")
+	}
+
+	results := make([]ConfigParams, 0, len(node.Content))
+	for index, item := range node.Content {
+		path := fmt.Sprintf("%s[%d]", p.fieldPath(key), index)
+		if item.Kind != yaml.MappingNode {
+			return nil, p.errorAt(item, path, "expected a mapping")
+		}
+
+		child := newConfigParams(p.fileName, path, item)
+		p.children = append(p.children, child)
+		results = append(results, child)
+	}
+
+	return results, nil
+}
+
+func (p *configParams) Errorf(key string, format string, values ...interface{}) error {
+	node := p.node
+	for index := 0; index+1 < len(p.node.Content); index += 2 {
+		if p.node.Content[index].Value == key {
+			node = p.node.Content[index+1]
+			break
+		}
+	}
+
+	return p.errorAt(node, p.fieldPath(key), fmt.Sprintf(format, values...))
+}
+
+func (p *configParams) errorAt(node *yaml.Node, path string, message string) error {
+	return fmt.Errorf("nagopher: %s:%d:%d: %s: %s", p.fileName, node.Line, node.Column, path, message)
+}
+
+func (p *configParams) fieldPath(key string) string {
+	if p.path == "" {
+		return key
+	}
+
+	return p.path + "." + key
+}
+
+// checkUnknownFields reports the first field, which has not been accessed by the loader or a factory. Opaque params
+// belong to resources which have only been validated, so their fields are skipped.
+func (p *configParams) checkUnknownFields() error {
+	if p.opaque {
+		return nil
+	}
+
+	for index := 0; index+1 < len(p.node.Content); index += 2 {
+		keyNode := p.node.Content[index]
+		if !p.used[keyNode.Value] {
+			return p.errorAt(keyNode, p.fieldPath(keyNode.Value), "unknown field")
+		}
+	}
+
+	for _, child := range p.children {
+		if err := child.checkUnknownFields(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s configSummarizer) Ok(check Check) string {
+	if s.okMessage != "" {
+		return s.okMessage
+	}
+
+	return s.Summarizer.Ok(check)
+}
+
+func (s configSummarizer) Empty() string {
+	if s.emptyMessage != "" {
+		return s.emptyMessage
+	}
+
+	return s.Summarizer.Empty()
+}
+
+func newSummarizerFromConfig(params ConfigParams) (Summarizer, error) {
+	okMessage, err := params.String("ok", "")
+	if err != nil {
+		return nil, err
+	}
+	emptyMessage, err := params.String("empty", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &configSummarizer{Summarizer: NewSummarizer(), okMessage: okMessage, emptyMessage: emptyMessage}, nil
+}
+
+func newScalarContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	warningThreshold, err := params.Bounds("warning")
+	if err != nil {
+		return nil, err
+	}
+	criticalThreshold, err := params.Bounds("critical")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewScalarContext(name, warningThreshold, criticalThreshold, options...), nil
+}
+
+func newStringInfoContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	return NewStringInfoContext(name, options...), nil
+}
+
+func newStringMatchContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	problemState, err := params.State("state", StateCritical())
+	if err != nil {
+		return nil, err
+	}
+	expectedValues, err := params.Strings("expected")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStringMatchContext(name, problemState, expectedValues, options...), nil
+}
+
+func newHistogramContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	quantileParams, err := params.List("quantiles")
+	if err != nil {
+		return nil, err
+	}
+
+	quantiles := make([]HistogramQuantile, 0, len(quantileParams))
+	for _, quantileParam := range quantileParams {
+		if !quantileParam.Has("quantile") {
+			return nil, quantileParam.Errorf("quantile", "field is required")
+		}
+
+		quantile, err := quantileParam.Float("quantile", 0)
+		if err != nil {
+			return nil, err
+		} else if quantile < 0 || quantile > 1 {
+			return nil, quantileParam.Errorf("quantile", "quantile must be between 0 and 1")
+		}
+
+		warningThreshold, err := quantileParam.Bounds("warning")
+		if err != nil {
+			return nil, err
+		}
+		criticalThreshold, err := quantileParam.Bounds("critical")
+		if err != nil {
+			return nil, err
+		}
+
+		quantiles = append(quantiles, NewHistogramQuantile(quantile, warningThreshold, criticalThreshold))
+	}
+
+	countWarningThreshold, err := params.Bounds("count_warning")
+	if err != nil {
+		return nil, err
+	}
+	countCriticalThreshold, err := params.Bounds("count_critical")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHistogramContext(name, quantiles, countWarningThreshold, countCriticalThreshold, options...), nil
+}
+
+func newEnumContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	defaultState, err := params.State("default_state", StateUnknown())
+	if err != nil {
+		return nil, err
+	}
+
+	mappingParams, err := params.List("mappings")
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make([]EnumMapping, 0, len(mappingParams))
+	for _, mappingParam := range mappingParams {
+		state, err := mappingParam.State("state", StateOk())
+		if err != nil {
+			return nil, err
+		}
+		label, err := mappingParam.String("label", "")
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case mappingParam.Has("value"):
+			value, err := mappingParam.String("value", "")
+			if err != nil {
+				return nil, err
+			}
+			mappings = append(mappings, NewEnumValueMapping(value, state, label))
+
+		case mappingParam.Has("range"):
+			bounds, err := mappingParam.Bounds("range")
+			if err != nil {
+				return nil, err
+			}
+			mappings = append(mappings, NewEnumRangeMapping(*bounds, state, label))
+
+		case mappingParam.Has("pattern"):
+			value, err := mappingParam.String("pattern", "")
+			if err != nil {
+				return nil, err
+			}
+			pattern, err := regexp.Compile(value)
+			if err != nil {
+				return nil, mappingParam.Errorf("pattern", "invalid regular expression [%s]", value)
+			}
+			mappings = append(mappings, NewEnumPatternMapping(pattern, state, label))
+
+		default:
+			return nil, mappingParam.Errorf("value", "field is required, unless range or pattern is given")
+		}
+	}
+
+	return NewEnumContext(name, mappings, defaultState, options...), nil
+}
+
+func newVersionContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	warningConstraint, err := parseVersionConstraintParam(params, "warning")
+	if err != nil {
+		return nil, err
+	}
+	criticalConstraint, err := parseVersionConstraintParam(params, "critical")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVersionContext(name, warningConstraint, criticalConstraint, options...), nil
+}
+
+func parseVersionConstraintParam(params ConfigParams, key string) (VersionConstraint, error) {
+	if !params.Has(key) {
+		return nil, nil
+	}
+
+	specifier, err := params.String(key, "")
+	if err != nil {
+		return nil, err
+	}
+
+	constraint, err := ParseVersionConstraint(specifier)
+	if err != nil {
+		return nil, params.Errorf(key, "%s", err.Error())
+	}
+
+	return constraint, nil
+}
+
+func newSetContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	expectedMembers, err := params.Strings("expected")
+	if err != nil {
+		return nil, err
+	}
+	missingState, err := params.State("missing_state", StateCritical())
+	if err != nil {
+		return nil, err
+	}
+	unexpectedState, err := params.State("unexpected_state", StateWarning())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSetContext(name, expectedMembers, missingState, unexpectedState, options...), nil
+}
+
+func newDurationContextFromConfig(name string, params ConfigParams, options ...ContextOpt) (Context, error) {
+	warningThreshold, err := parseDurationBoundsParam(params, "warning")
+	if err != nil {
+		return nil, err
+	}
+	criticalThreshold, err := parseDurationBoundsParam(params, "critical")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDurationContext(name, warningThreshold, criticalThreshold, options...), nil
+}
+
+func parseDurationBoundsParam(params ConfigParams, key string) (*Bounds, error) {
+	if !params.Has(key) {
+		return nil, nil
+	}
+
+	specifier, err := params.String(key, "")
+	if err != nil {
+		return nil, err
+	}
+
+	bounds, err := NewDurationBounds(specifier)
+	if err != nil {
+		return nil, params.Errorf(key, "invalid duration range [%s]", specifier)
+	}
+
+	return &bounds, nil
+}
+
+func sortedConfigTypes(factories interface{}) []string {
+	var typeNames []string
+
+	switch typedFactories := factories.(type) {
+	case map[string]ResourceFactory:
+		for typeName := range typedFactories {
+			typeNames = append(typeNames, typeName)
+		}
+	case map[string]ContextFactory:
+		for typeName := range typedFactories {
+			typeNames = append(typeNames, typeName)
+		}
+	case map[string]SummarizerFactory:
+		for typeName := range typedFactories {
+			typeNames = append(typeNames, typeName)
+		}
+	}
+
+	sort.Strings(typeNames)
+	return typeNames
+}
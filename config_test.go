@@ -0,0 +1,327 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type mockConfigResource struct {
+	Resource
+	name  string
+	value float64
+}
+
+const mockCheckConfigYAML = `
+name: usage
+output: checkmk
+verbose: true
+summarizer:
+  ok: All values are fine
+resources:
+  - type: mock
+    name: usage1
+    value: 49.4
+  - type: mock
+    name: usage2
+    value: 92.6
+contexts:
+  - type: scalar
+    name: usage
+    warning: "10:80"
+    critical: 95
+    format: "%<name>s is at %<value>s%<unit>s"
+`
+
+func TestConfigRegistry_ParseCheckConfig(t *testing.T) {
+	// given
+	registry := newMockConfigRegistry()
+
+	// when
+	config, err := registry.ParseCheckConfig("check.yaml", []byte(mockCheckConfigYAML))
+	check, checkErr := config.NewCheck()
+	result := config.Runtime().Execute(check)
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, checkErr)
+	assert.Equal(t, "usage", config.Name())
+	assert.IsType(t, &checkmkRuntime{}, config.Runtime())
+	assert.Equal(t, StateWarning().ExitCode(), result.ExitCode())
+	assert.Equal(t, `1 "usage" usage1=49.4;10:80;95|usage2=92.6;10:80;95 usage2 is at 92.6% (outside range 10:80)`+
+		`\nwarning: usage2 is at 92.6% (outside range 10:80)`+"\n", result.Output())
+}
+
+func TestConfigRegistry_ParseCheckConfig_JSON(t *testing.T) {
+	// given
+	registry := newMockConfigRegistry()
+	data := `{
+		"name": "usage",
+		"summarizer": {"type": "default", "ok": "All values are fine"},
+		"resources": [{"type": "mock", "name": "usage1", "value": 49.4}],
+		"contexts": [{"type": "scalar", "name": "usage", "warning": "80"}]
+	}`
+
+	// when
+	config, err := registry.ParseCheckConfig("check.json", []byte(data))
+	check, _ := config.NewCheck()
+	result := config.Runtime().Execute(check)
+
+	// then
+	assert.NoError(t, err)
+	assert.IsType(t, &baseRuntime{}, config.Runtime())
+	assert.Equal(t, "USAGE OK - All values are fine | usage1=49.4%;:80\n", result.Output())
+}
+
+func TestConfigRegistry_LoadCheckConfig(t *testing.T) {
+	// given
+	directory := createTempDirectory(t)
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "check.yaml")
+	_ = ioutil.WriteFile(path, []byte(mockCheckConfigYAML), 0600)
+	registry := newMockConfigRegistry()
+
+	// when
+	config, err1 := registry.LoadCheckConfig(path)
+	_, err2 := registry.LoadCheckConfig(filepath.Join(directory, "missing.yaml"))
+
+	// then
+	assert.NoError(t, err1)
+	assert.Equal(t, "usage", config.Name())
+	assert.Error(t, err2)
+}
+
+func TestConfigRegistry_ParseCheckConfig_BuiltinContexts(t *testing.T) {
+	// given
+	registry := newMockConfigRegistry()
+	data := `
+resources:
+  - type: mock
+contexts:
+  - type: string-info
+    name: info
+  - type: string-match
+    name: status
+    state: warning
+    expected: [online, standby]
+  - type: histogram
+    name: latency
+    quantiles:
+      - quantile: 0.5
+        warning: 100
+      - quantile: 0.99
+        critical: 500
+    count_warning: "1:"
+  - type: scalar
+    name: disk
+    thresholds: "/var=80:90,*=70:80"
+  - type: enum
+    name: raid
+    default_state: critical
+    mappings:
+      - value: 0
+        label: optimal
+      - range: "1:2"
+        state: warning
+        label: degraded
+      - pattern: "^fail"
+        state: critical
+        label: failed
+  - type: version
+    name: openssl
+    warning: "< 1.1.1k"
+    critical: "=3.0.0"
+  - type: set
+    name: units
+    expected: [sshd.service, cron.service]
+    unexpected_state: ok
+  - type: duration
+    name: response
+    warning: "500ms:2s"
+    critical: 5s
+`
+
+	// when
+	config, err := registry.ParseCheckConfig("check.yaml", []byte(data))
+	check, _ := config.NewCheck()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 8, len(check.Contexts()))
+}
+
+func TestConfigRegistry_ParseCheckConfig_Errors(t *testing.T) {
+	testCases := []struct {
+		data  string
+		error string
+	}{
+		{"name: [", "nagopher: check.yaml: yaml: line 1: did not find expected node content"},
+		{"- usage", "nagopher: check.yaml: config must contain a mapping of check settings"},
+		{"output: json\nresources: [{type: mock}]",
+			"nagopher: check.yaml:1:9: output: unknown output mode [json], expected one of [nagios, checkmk]"},
+		{"name: usage", "nagopher: check.yaml:1:1: resources: at least one resource must be defined"},
+		{"resources: {type: mock}", "nagopher: check.yaml:1:12: resources: expected a list"},
+		{"resources:\n  - type: disk",
+			"nagopher: check.yaml:2:11: resources[0].type: unknown resource type [disk], expected one of [mock]"},
+		{"resources:\n  - name: usage1", "nagopher: check.yaml:2:5: resources[0].type: field is required"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: scalar\n    name: usage\n    warning: \"a:b\"",
+			"nagopher: check.yaml:5:14: contexts[0].warning: invalid nagios range [a:b]"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: unknown\n    name: usage",
+			"nagopher: check.yaml:3:11: contexts[0].type: unknown context type [unknown], expected one of " +
+				"[duration, enum, histogram, scalar, set, string-info, string-match, version]"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: string-match\n    name: status\n    state: broken",
+			"nagopher: check.yaml:5:12: contexts[0].state: unknown state [broken], expected one of " +
+				"[ok, info, warning, critical, unknown]"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: histogram\n    name: latency\n    quantiles:\n      - quantile: 2",
+			"nagopher: check.yaml:6:19: contexts[0].quantiles[0].quantile: quantile must be between 0 and 1"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: enum\n    name: raid\n    mappings:\n      - label: ok",
+			"nagopher: check.yaml:6:9: contexts[0].mappings[0].value: field is required, unless range or pattern is given"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: version\n    name: openssl\n    warning: \"<x\"",
+			"nagopher: check.yaml:5:14: contexts[0].warning: invalid version constraint [<x] (version [x] must start with a digit)"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: duration\n    name: response\n    critical: 5 parsecs",
+			"nagopher: check.yaml:5:15: contexts[0].critical: invalid duration range [5 parsecs]"},
+		{"resources: [{type: mock}]\ncontexts:\n  - type: scalar\n    name: usage\n    labels: [a, b]",
+			"nagopher: check.yaml:5:13: contexts[0].labels: expected a mapping of label names to values"},
+		{"resources: [{type: mock}]\nsummarizer:\n  type: custom",
+			"nagopher: check.yaml:3:9: summarizer.type: unknown summarizer type [custom], expected one of [default]"},
+		{"resources: [{type: mock}]\nverbose: maybe", "nagopher: check.yaml:2:10: verbose: invalid boolean [maybe]"},
+		{"resources: [{type: mock}]\nname: [usage]", "nagopher: check.yaml:2:7: name: expected a single value"},
+	}
+
+	for _, testCase := range testCases {
+		// given
+		registry := newMockConfigRegistry()
+
+		// when
+		config, err := registry.ParseCheckConfig("check.yaml", []byte(testCase.data))
+
+		// then
+		assert.Nil(t, config, testCase.data)
+		assert.EqualError(t, err, testCase.error, testCase.data)
+	}
+}
+
+func TestCheckConfig_NewCheck_Errors(t *testing.T) {
+	testCases := []struct {
+		data  string
+		error string
+	}{
+		{"resources:\n  - type: mock\n    value: high",
+			"nagopher: check.yaml:3:12: resources[0].value: invalid number [high]"},
+		{"resources:\n  - type: mock\n    colour: red", "nagopher: check.yaml:3:5: resources[0].colour: unknown field"},
+	}
+
+	for _, testCase := range testCases {
+		// given
+		registry := newMockConfigRegistry()
+		config, err := registry.ParseCheckConfig("check.yaml", []byte(testCase.data))
+
+		// when
+		check, checkErr := config.NewCheck()
+
+		// then
+		assert.NoError(t, err, testCase.data)
+		assert.Nil(t, check, testCase.data)
+		assert.EqualError(t, checkErr, testCase.error, testCase.data)
+	}
+}
+
+func TestCheckConfig_NewCheck_ResourceFactory(t *testing.T) {
+	// given
+	var calls int
+	registry := NewConfigRegistry()
+	registry.RegisterResource("mock", func(params ConfigParams) (Resource, error) {
+		calls++
+		registry.RegisterResource("nested", nil)
+		return newMockResource(), nil
+	})
+
+	// when
+	config, err := registry.ParseCheckConfig("check.yaml", []byte("resources: [{type: mock}]"))
+	parseCalls := calls
+	_, checkErr := config.NewCheck()
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, checkErr)
+	assert.Equal(t, 0, parseCalls)
+	assert.Equal(t, 1, calls)
+}
+
+func TestConfigParams(t *testing.T) {
+	// given
+	registry := NewConfigRegistry()
+	var params ConfigParams
+	registry.RegisterResource("capture", func(p ConfigParams) (Resource, error) {
+		params = p
+		return NewResource(), nil
+	})
+
+	// when
+	config, _ := registry.ParseCheckConfig("params.yaml", []byte("resources:\n  - type: capture\n    "+
+		"duration: 1m30s\n    invalid: soon\n    list: single\n    nested: [[a]]\n    text: [a]"))
+	_, _ = config.NewCheck()
+	duration, err1 := params.Duration("duration", 0)
+	_, err2 := params.Duration("invalid", 0)
+	list, err3 := params.Strings("list")
+	_, err4 := params.Strings("nested")
+	_, err5 := params.Strings("text")
+	defaultDuration, err6 := params.Duration("missing", 5)
+
+	// then
+	assert.NoError(t, err1)
+	assert.Equal(t, "1m30s", duration.String())
+	assert.EqualError(t, err2, "nagopher: params.yaml:4:14: resources[0].invalid: invalid duration [soon]")
+	assert.NoError(t, err3)
+	assert.Equal(t, []string{"single"}, list)
+	assert.EqualError(t, err4, "nagopher: params.yaml:6:14: resources[0].nested[0]: expected a single value")
+	assert.NoError(t, err5)
+	assert.NoError(t, err6)
+	assert.Equal(t, 5, int(defaultDuration))
+}
+
+func newMockConfigRegistry() ConfigRegistry {
+	registry := NewConfigRegistry()
+	registry.RegisterResource("mock", func(params ConfigParams) (Resource, error) {
+		name, err := params.String("name", "usage")
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := params.Float("value", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mockConfigResource{Resource: NewResource(), name: name, value: value}, nil
+	})
+
+	return registry
+}
+
+func (r mockConfigResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	return []Metric{
+		MustNewNumericMetric(r.name, r.value, "%", nil, "usage"),
+	}, nil
+}
@@ -20,6 +20,7 @@ package nagopher
 
 import (
 	"github.com/chonla/format"
+	"time"
 )
 
 // Context provides methods for further processing a metric to generate results and/or performance data
@@ -30,38 +31,153 @@ type Context interface {
 	Performance(Metric, Resource) (OptionalPerfData, error)
 }
 
+// LabelSelectorContext is a Context which only applies to metrics carrying all labels of its selector
+type LabelSelectorContext interface {
+	Context
+	LabelSelector() Labels
+}
+
+// ContextOpt is a type alias for functional options used by all context constructors
+type ContextOpt func(*baseContext)
+
+// MultiPerfDataContext is an optional extension of Context, which allows a single metric to be expanded into several
+// performance data entries. Check will prefer MultiPerformance() over Performance() for contexts implementing it.
+type MultiPerfDataContext interface {
+	Context
+
+	MultiPerformance(Metric, Resource) ([]PerfData, error)
+}
+
 type baseContext struct {
-	name   string
-	format string
+	name          string
+	format        string
+	labelSelector Labels
+	thresholdMap  ThresholdMap
+	transformers  []MetricTransformer
+	clock         func() time.Time
+
+	undefinedValuePolicy *undefinedValuePolicy
 }
 
 // NewBaseContext instantiates a base Context which neither holds any information nor provides any kind of logic. It is
 // meant to be used for developing custom Context types outside of nagopher.
-func NewBaseContext(name string, format string) Context {
-	return newBaseContext(name, format)
+func NewBaseContext(name string, format string, options ...ContextOpt) Context {
+	return newBaseContext(name, format, options...)
 }
 
-func newBaseContext(name string, format string) *baseContext {
+func newBaseContext(name string, format string, options ...ContextOpt) *baseContext {
 	baseContext := &baseContext{
 		name:   name,
 		format: format,
 	}
 
+	for _, option := range options {
+		option(baseContext)
+	}
+
 	return baseContext
 }
 
+// ContextLabelSelector is a functional option for context constructors, which restricts the context to metrics carrying
+// all of the given labels. When several contexts share the same name, the most specific matching selector wins.
+func ContextLabelSelector(selector Labels) ContextOpt {
+	return func(c *baseContext) {
+		c.labelSelector = selector.Copy()
+	}
+}
+
+// ContextThresholdMap is a functional option for context constructors, which resolves the warning and critical thresholds
+// per metric using the given ThresholdMap. Contexts fall back to their own thresholds when no rule of the map matches.
+// This option is only being honored by contexts supporting thresholds, e.g. ScalarContext and DeltaContext.
+func ContextThresholdMap(thresholdMap ThresholdMap) ContextOpt {
+	return func(c *baseContext) {
+		c.thresholdMap = thresholdMap
+	}
+}
+
+// ContextTransformers is a functional option for context constructors, which transforms each NumericMetric using the
+// given transformers in order, before the resulting metric gets evaluated by the context and used for performance data
+func ContextTransformers(transformers ...MetricTransformer) ContextOpt {
+	return func(c *baseContext) {
+		c.transformers = append(c.transformers, transformers...)
+	}
+}
+
+// ContextClock is a functional option for context constructors, which replaces the clock being used for resolving
+// ScheduledBounds during evaluation. It defaults to time.Now() and is mostly useful for testing purposes.
+func ContextClock(clock func() time.Time) ContextOpt {
+	return func(c *baseContext) {
+		c.clock = clock
+	}
+}
+
+// ContextFormat is a functional option for context constructors, which overrides the format used by Describe(). Besides
+// the placeholders %<name>s, %<value>s and %<unit>s, all metric labels are available either as %<label>s or {label}.
+func ContextFormat(format string) ContextOpt {
+	return func(c *baseContext) {
+		c.format = format
+	}
+}
+
 func (c baseContext) Name() string {
 	return c.name
 }
 
+func (c baseContext) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+
+	return time.Now()
+}
+
+// fixedClock returns a clock, which always returns the time the context clock had when calling this method. Contexts
+// resolving ScheduledBounds use it to evaluate a metric and generate its performance data at the same point in time.
+func (c baseContext) fixedClock() func() time.Time {
+	now := c.now()
+	return func() time.Time { return now }
+}
+
+func (c baseContext) metricTransformers() []MetricTransformer {
+	return c.transformers
+}
+
+func (c baseContext) undefinedValue() *undefinedValuePolicy {
+	return c.undefinedValuePolicy
+}
+
+func (c baseContext) LabelSelector() Labels {
+	return c.labelSelector
+}
+
+func contextLabelSelector(context Context) Labels {
+	if selectorContext, ok := context.(LabelSelectorContext); ok {
+		return selectorContext.LabelSelector()
+	}
+
+	return nil
+}
+
 func (c baseContext) Describe(metric Metric) string {
-	data := map[string]interface{}{
-		"name":  metric.Name(),
-		"value": metric.ValueString(),
-		"unit":  metric.ValueUnit(),
+	return c.describe(metric, nil)
+}
+
+// describe renders the format of the context using the given metric, while the extra values allow contexts to provide
+// additional placeholders
+func (c baseContext) describe(metric Metric, extra map[string]interface{}) string {
+	data := make(map[string]interface{})
+	for key, value := range metricLabels(metric) {
+		data[key] = value
+	}
+
+	data["name"] = metric.Name()
+	data["value"] = metric.ValueString()
+	data["unit"] = metric.ValueUnit()
+	for key, value := range extra {
+		data[key] = value
 	}
 
-	return format.Sprintf(c.format, data)
+	return format.Sprintf(metricLabels(metric).Render(c.format), data)
 }
 
 func (c baseContext) Evaluate(metric Metric, resource Resource) Result {
@@ -32,8 +32,8 @@ type deltaContext struct {
 // NewDeltaContext creates a new scalar Context object, which operates the same way as a ScalarContext, but instead
 // of using the current absolute metric value, it will be compared to a previous measurement. It is the callers duty
 // to provide a pointer to the previous metric value or nil, if not available.
-func NewDeltaContext(name string, previousValue *float64, warningThreshold *Bounds, criticalThreshold *Bounds) Context {
-	baseContext := NewScalarContext(name, warningThreshold, criticalThreshold)
+func NewDeltaContext(name string, previousValue *float64, warningThreshold *Bounds, criticalThreshold *Bounds, options ...ContextOpt) Context {
+	baseContext := NewScalarContext(name, warningThreshold, criticalThreshold, options...)
 	scalarContext := baseContext.(*scalarContext)
 	deltaContext := &deltaContext{
 		scalarContext: *scalarContext,
@@ -61,23 +61,25 @@ func (c *deltaContext) Evaluate(metric Metric, resource Resource) Result {
 	}
 
 	deltaValue := metricValue - previousValue
-	deltaMetric := MustNewNumericMetric(numericMetric.Name()+"_delta", deltaValue, "", nil, numericMetric.ContextName())
+	deltaMetric := MustNewNumericMetric(numericMetric.Name()+"_delta", deltaValue, "", nil, numericMetric.ContextName(),
+		MetricLabels(metricLabels(numericMetric)))
 
 	emptyBounds := NewBounds()
-	warningThreshold := c.warningThreshold.OrElse(emptyBounds)
-	criticalThreshold := c.criticalThreshold.OrElse(emptyBounds)
+	optionalWarningThreshold, optionalCriticalThreshold := c.resolveThresholds(metric)
+	warningThreshold := optionalWarningThreshold.OrElse(emptyBounds)
+	criticalThreshold := optionalCriticalThreshold.OrElse(emptyBounds)
 
 	if !criticalThreshold.Match(deltaValue) {
 		return NewResult(
 			ResultState(StateCritical()),
 			ResultMetric(deltaMetric), ResultContext(c), ResultResource(resource),
-			ResultHint(criticalThreshold.ViolationHint()),
+			ResultHint(thresholdViolationHint(criticalThreshold, deltaValue)),
 		)
 	} else if !warningThreshold.Match(deltaValue) {
 		return NewResult(
 			ResultState(StateWarning()),
 			ResultMetric(deltaMetric), ResultContext(c), ResultResource(resource),
-			ResultHint(warningThreshold.ViolationHint()),
+			ResultHint(thresholdViolationHint(warningThreshold, deltaValue)),
 		)
 	}
 
@@ -87,6 +89,11 @@ func (c *deltaContext) Evaluate(metric Metric, resource Resource) Result {
 	)
 }
 
+func (c deltaContext) withFixedClock() Context {
+	c.clock = c.fixedClock()
+	return &c
+}
+
 func (c deltaContext) Performance(metric Metric, resource Resource) (OptionalPerfData, error) {
 	perfData, err := NewPerfData(metric, nil, nil)
 	if err != nil {
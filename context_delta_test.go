@@ -21,6 +21,7 @@ package nagopher
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestDeltaContext_Evaluate(t *testing.T) {
@@ -100,3 +101,25 @@ func TestDeltaContext_Performance(t *testing.T) {
 	assert.Implements(t, (*PerfData)(nil), perfData1)
 	assert.Nil(t, perfData2)
 }
+
+func TestDeltaContext_ScheduledBounds(t *testing.T) {
+	// given
+	now := time.Date(2019, 6, 22, 12, 0, 0, 0, time.UTC)
+	previousValue := float64(100)
+	weekendWindow, _ := ParseCronWindow("* * * * sat,sun", time.UTC)
+	weekdayThreshold, _ := NewBoundsFromNagiosRange("-5:5")
+	weekendThreshold, _ := NewBoundsFromNagiosRange("-1:1")
+	warningThreshold := Bounds(NewScheduledBounds(weekdayThreshold, BoundsDuring(weekendWindow, weekendThreshold)))
+	context := NewDeltaContext("context", &previousValue, &warningThreshold, nil, ContextClock(func() time.Time { return now }))
+	resource := NewResource()
+
+	// when
+	result1 := context.Evaluate(MustNewNumericMetric("metric", 103, "", nil, ""), resource)
+	now = now.AddDate(0, 0, 2)
+	result2 := context.Evaluate(MustNewNumericMetric("metric", 106, "", nil, ""), resource)
+
+	// then
+	assert.Equal(t, StateWarning(), result1.State().OrElse(nil))
+	assert.Equal(t, "outside range -1:1", result1.Hint())
+	assert.Equal(t, StateOk(), result2.State().OrElse(nil))
+}
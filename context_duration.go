@@ -0,0 +1,219 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type durationContext struct {
+	scalarContext
+}
+
+type durationBounds struct {
+	Bounds
+}
+
+// NewDurationBounds is a helper method, which constructs new Bounds from a Nagios range specifier containing durations
+// as supported by time.ParseDuration(), e.g. "500ms:2s" or "@1m:5m". Plain numbers are interpreted as seconds. The
+// resulting bounds match against seconds and describe themselves using durations.
+func NewDurationBounds(specifier string) (Bounds, error) {
+	var options []BoundsOpt
+	var lowerPart, upperPart string
+
+	if strings.HasPrefix(specifier, "@") {
+		options = append(options, InvertedBounds(true))
+		specifier = specifier[1:]
+	}
+
+	parts := strings.Split(specifier, ":")
+	if len(parts) == 1 {
+		lowerPart, upperPart = "", parts[0]
+	} else if len(parts) == 2 {
+		lowerPart, upperPart = parts[0], parts[1]
+	} else {
+		return nil, fmt.Errorf("duration range specifier [%s] must contain only one colon", specifier)
+	}
+
+	lowerBound, err := parseDurationRangePart(lowerPart, true)
+	if err != nil {
+		return nil, err
+	}
+	upperBound, err := parseDurationRangePart(upperPart, false)
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(options, LowerBound(lowerBound), UpperBound(upperBound))
+	return &durationBounds{Bounds: NewBounds(options...)}, nil
+}
+
+func parseDurationRangePart(rangePart string, isStart bool) (float64, error) {
+	if rangePart == "" || rangePart == "~" {
+		return parseNagiosRangePart(rangePart, isStart)
+	}
+
+	if value, err := strconv.ParseFloat(rangePart, strconv.IntSize); err == nil {
+		return value, nil
+	}
+
+	duration, err := time.ParseDuration(rangePart)
+	if err != nil {
+		return math.NaN(), fmt.Errorf("could not parse range part [%s] as duration (%s)", rangePart, err.Error())
+	}
+
+	return duration.Seconds(), nil
+}
+
+func formatDurationBound(value float64) string {
+	if math.IsInf(value, 0) {
+		return "~"
+	}
+
+	return time.Duration(value * float64(time.Second)).String()
+}
+
+func (b durationBounds) boundsToString() string {
+	var lower, upper string
+	b.Lower().If(func(value float64) { lower = formatDurationBound(value) })
+	b.Upper().If(func(value float64) {
+		if !math.IsInf(value, 1) {
+			upper = formatDurationBound(value)
+		}
+	})
+
+	return lower + ":" + upper
+}
+
+func (b durationBounds) String() string {
+	if b.IsInverted() {
+		return "outside range " + b.boundsToString()
+	}
+
+	return "inside range " + b.boundsToString()
+}
+
+func (b durationBounds) ViolationHint() string {
+	if b.IsInverted() {
+		return "inside range " + b.boundsToString()
+	}
+
+	return "outside range " + b.boundsToString()
+}
+
+// NewDurationContext creates a new Context object, which handles metrics of the type DurationMetric. The thresholds
+// are expressed in seconds, which is the case for bounds created by NewDurationBounds(). Describe() renders the value as
+// human-readable duration, while performance data uses the unit of the metric, including the converted thresholds.
+func NewDurationContext(name string, warningThreshold *Bounds, criticalThreshold *Bounds, options ...ContextOpt) Context {
+	durationContext := &durationContext{
+		scalarContext: scalarContext{
+			baseContext: *newBaseContext(name, "%<name>s is %<value>s", options...),
+		},
+	}
+
+	if warningThreshold != nil {
+		durationContext.warningThreshold = NewOptionalBounds(*warningThreshold)
+	}
+	if criticalThreshold != nil {
+		durationContext.criticalThreshold = NewOptionalBounds(*criticalThreshold)
+	}
+
+	return durationContext
+}
+
+func (c durationContext) Describe(metric Metric) string {
+	durationMetric, ok := metric.(DurationMetric)
+	if !ok {
+		return c.baseContext.Describe(metric)
+	}
+
+	return c.describe(metric, map[string]interface{}{"value": durationMetric.Duration().String()})
+}
+
+func (c durationContext) Evaluate(metric Metric, resource Resource) Result {
+	durationMetric, ok := metric.(DurationMetric)
+	if !ok {
+		return NewResult(
+			ResultState(StateUnknown()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(fmt.Sprintf("DurationContext can not process metric of type [%s]", reflect.TypeOf(metric))),
+		)
+	}
+
+	emptyBounds := NewBounds()
+	optionalWarningThreshold, optionalCriticalThreshold := c.resolveThresholds(metric)
+	warningThreshold := optionalWarningThreshold.OrElse(emptyBounds)
+	criticalThreshold := optionalCriticalThreshold.OrElse(emptyBounds)
+	seconds := durationMetric.Duration().Seconds()
+
+	if !criticalThreshold.Match(seconds) {
+		return NewResult(
+			ResultState(StateCritical()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(thresholdViolationHint(criticalThreshold, seconds)),
+		)
+	} else if !warningThreshold.Match(seconds) {
+		return NewResult(
+			ResultState(StateWarning()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(thresholdViolationHint(warningThreshold, seconds)),
+		)
+	}
+
+	return NewResult(
+		ResultState(StateOk()),
+		ResultMetric(metric), ResultContext(c), ResultResource(resource),
+	)
+}
+
+func (c durationContext) withFixedClock() Context {
+	c.clock = c.fixedClock()
+	return &c
+}
+
+func (c durationContext) Performance(metric Metric, resource Resource) (OptionalPerfData, error) {
+	unit, ok := durationUnits[metric.ValueUnit()]
+	if _, isDuration := metric.(DurationMetric); !isDuration || !ok {
+		return c.scalarContext.Performance(metric, resource)
+	}
+
+	factor := float64(time.Second) / float64(unit)
+	convert := func(threshold OptionalBounds) *Bounds {
+		bounds, err := threshold.Get()
+		if err != nil {
+			return nil
+		}
+
+		converted := convertBounds(bounds, func(value float64) float64 { return value * factor })
+		return &converted
+	}
+
+	warningThreshold, criticalThreshold := c.resolveThresholds(metric)
+	perfData, err := NewPerfData(metric, convert(warningThreshold), convert(criticalThreshold))
+	if err != nil {
+		return OptionalPerfData{}, err
+	}
+
+	return NewOptionalPerfData(perfData), nil
+}
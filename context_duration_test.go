@@ -0,0 +1,110 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewDurationBounds(t *testing.T) {
+	// given
+	specifiers := map[string][]interface{}{
+		"500ms:2s": {0.5, 2.0, false, "inside range 500ms:2s"},
+		"2s":       {0.0, 2.0, false, "inside range 0s:2s"},
+		"@1m:5m":   {60.0, 300.0, true, "outside range 1m0s:5m0s"},
+		"~:1.5":    {math.Inf(-1), 1.5, false, "inside range ~:1.5s"},
+		"10s:":     {10.0, math.Inf(1), false, "inside range 10s:"},
+	}
+
+	for specifier, expected := range specifiers {
+		// when
+		bounds, err := NewDurationBounds(specifier)
+
+		// then
+		assert.NoError(t, err, specifier)
+		assert.Equal(t, expected[0], bounds.Lower().OrElse(math.NaN()), specifier)
+		assert.Equal(t, expected[1], bounds.Upper().OrElse(math.NaN()), specifier)
+		assert.Equal(t, expected[2], bounds.IsInverted(), specifier)
+		assert.Equal(t, expected[3], bounds.String(), specifier)
+	}
+}
+
+func TestNewDurationBounds_Invalid(t *testing.T) {
+	for _, specifier := range []string{"1s:2s:3s", "5 parsecs", "1s:~", "fast:"} {
+		// when
+		_, err := NewDurationBounds(specifier)
+
+		// then
+		assert.Error(t, err, specifier)
+	}
+}
+
+func TestDurationContext_Evaluate(t *testing.T) {
+	// given
+	warningThreshold, _ := NewDurationBounds("500ms")
+	criticalThreshold, _ := NewDurationBounds("2s")
+	context := NewDurationContext("response", &warningThreshold, &criticalThreshold)
+	resource := NewResource()
+
+	// when
+	result1 := context.Evaluate(MustNewDurationMetric("response", 250*time.Millisecond, ""), resource)
+	result2 := context.Evaluate(MustNewDurationMetric("response", 1500*time.Millisecond, ""), resource)
+	result3 := context.Evaluate(MustNewDurationMetric("response", 1*time.Minute+30*time.Second, ""), resource)
+	result4 := context.Evaluate(MustNewNumericMetric("response", 1, "s", nil, ""), resource)
+
+	// then
+	assert.Equal(t, StateOk(), result1.State().OrElse(nil))
+	assert.Equal(t, StateWarning(), result2.State().OrElse(nil))
+	assert.Equal(t, StateCritical(), result3.State().OrElse(nil))
+	assert.Equal(t, StateUnknown(), result4.State().OrElse(nil))
+
+	assert.Equal(t, "response is 250ms", result1.String())
+	assert.Equal(t, "response is 1.5s (outside range 0s:500ms)", result2.String())
+	assert.Equal(t, "response is 1m30s (outside range 0s:2s)", result3.String())
+	assert.Contains(t, result4.Hint(), "DurationContext can not process metric of type")
+}
+
+func TestDurationContext_Performance(t *testing.T) {
+	// given
+	warningThreshold, _ := NewDurationBounds("500ms:2s")
+	criticalThreshold, _ := NewDurationBounds("@1us:100us")
+	context := NewDurationContext("response", &warningThreshold, &criticalThreshold)
+	resource := NewResource()
+
+	// when
+	perfData1, err1 := context.Performance(MustNewDurationMetric("response", 250*time.Millisecond, "",
+		MetricDurationUnit("ms")), resource)
+	perfData2, err2 := context.Performance(MustNewDurationMetric("response", 3*time.Second, ""), resource)
+	perfData3, err3 := context.Performance(MustNewDurationMetric("response", 50*time.Microsecond, "",
+		MetricDurationUnit("us")), resource)
+	perfData4, err4 := context.Performance(MustNewDurationMetric("response", 250*time.Millisecond, ""), resource)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.NoError(t, err4)
+	assert.Equal(t, "response=250ms;500:2000;@0.001:0.1", perfData1.OrElse(nil).ToNagiosPerfData())
+	assert.Equal(t, "response=3s;0.5:2;@0.000001:0.0001", perfData2.OrElse(nil).ToNagiosPerfData())
+	assert.Equal(t, "response=50us;500000:2000000;@1:100", perfData3.OrElse(nil).ToNagiosPerfData())
+	assert.Equal(t, "response=0.25s;0.5:2;@0.000001:0.0001", perfData4.OrElse(nil).ToNagiosPerfData())
+}
@@ -0,0 +1,190 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EnumMapping maps a status code or value of a metric to a State and a human-readable label, which are being used by
+// an EnumContext for evaluating and describing the metric
+type EnumMapping interface {
+	Matches(Metric) bool
+	State() State
+	Label() string
+}
+
+type enumMapping struct {
+	match func(Metric) bool
+	state State
+	label string
+}
+
+type enumContext struct {
+	baseContext
+
+	mappings     []EnumMapping
+	defaultState State
+}
+
+const enumUnmappedLabel = "unmapped"
+
+// NewEnumValueMapping instantiates a new EnumMapping, which matches metrics whose value equals the given value. Both
+// numeric and string metrics are supported, while strings are compared case-insensitively.
+func NewEnumValueMapping(value string, state State, label string) EnumMapping {
+	return &enumMapping{
+		match: func(metric Metric) bool {
+			return strings.EqualFold(metric.ValueString(), value)
+		},
+		state: state,
+		label: label,
+	}
+}
+
+// NewEnumRangeMapping instantiates a new EnumMapping, which matches metrics with a numeric value inside the given bounds.
+// String metrics are matched as well, as long as their value can be parsed as a number.
+func NewEnumRangeMapping(bounds Bounds, state State, label string) EnumMapping {
+	return &enumMapping{
+		match: func(metric Metric) bool {
+			value, ok := enumNumericValue(metric)
+			return ok && bounds.Match(value)
+		},
+		state: state,
+		label: label,
+	}
+}
+
+// NewEnumPatternMapping instantiates a new EnumMapping, which matches metrics with a value matching the given regular
+// expression
+func NewEnumPatternMapping(pattern *regexp.Regexp, state State, label string) EnumMapping {
+	return &enumMapping{
+		match: func(metric Metric) bool {
+			return pattern.MatchString(metric.ValueString())
+		},
+		state: state,
+		label: label,
+	}
+}
+
+// NewEnumContext creates a new Context object, which maps status codes of NumericMetric or StringMetric values to
+// states using the given mappings. The first matching mapping wins, while unmapped values result in the default state.
+// Besides the usual placeholders, the format may contain %<label>s for the label of the matching mapping. The status
+// code gets exported as performance data, as long as it is numeric.
+func NewEnumContext(name string, mappings []EnumMapping, defaultState State, options ...ContextOpt) Context {
+	enumContext := &enumContext{
+		baseContext:  *newBaseContext(name, "%<name>s is %<label>s (code %<value>s)", options...),
+		mappings:     mappings,
+		defaultState: defaultState,
+	}
+
+	return enumContext
+}
+
+func enumNumericValue(metric Metric) (float64, bool) {
+	if numericMetric, ok := metric.(NumericMetric); ok {
+		return numericMetric.Value(), true
+	}
+
+	value, err := strconv.ParseFloat(metric.ValueString(), strconv.IntSize)
+	return value, err == nil
+}
+
+func (m enumMapping) Matches(metric Metric) bool {
+	return m.match(metric)
+}
+
+func (m enumMapping) State() State {
+	return m.state
+}
+
+func (m enumMapping) Label() string {
+	return m.label
+}
+
+func (c enumContext) resolveMapping(metric Metric) (EnumMapping, bool) {
+	for _, mapping := range c.mappings {
+		if mapping.Matches(metric) {
+			return mapping, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c enumContext) Describe(metric Metric) string {
+	label := enumUnmappedLabel
+	if mapping, ok := c.resolveMapping(metric); ok {
+		label = mapping.Label()
+	}
+
+	return c.describe(metric, map[string]interface{}{"label": label})
+}
+
+func (c enumContext) Evaluate(metric Metric, resource Resource) Result {
+	_, isNumeric := metric.(NumericMetric)
+	_, isString := metric.(StringMetric)
+	if !isNumeric && !isString {
+		return NewResult(
+			ResultState(StateUnknown()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(fmt.Sprintf("EnumContext can not process metric of type [%s]", reflect.TypeOf(metric))),
+		)
+	}
+
+	mapping, ok := c.resolveMapping(metric)
+	if !ok {
+		return NewResult(
+			ResultState(c.defaultState),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(fmt.Sprintf("unmapped value [%s]", metric.ValueString())),
+		)
+	}
+
+	return NewResult(
+		ResultState(mapping.State()),
+		ResultMetric(metric), ResultContext(c), ResultResource(resource),
+	)
+}
+
+func (c enumContext) Performance(metric Metric, resource Resource) (OptionalPerfData, error) {
+	if _, ok := metric.(NumericMetric); !ok {
+		value, ok := enumNumericValue(metric)
+		if !ok {
+			return OptionalPerfData{}, nil
+		}
+
+		numericMetric, err := NewNumericMetric(metric.Name(), value, metric.ValueUnit(), nil, metric.ContextName(),
+			MetricLabels(metricLabels(metric)))
+		if err != nil {
+			return OptionalPerfData{}, err
+		}
+		metric = numericMetric
+	}
+
+	perfData, err := NewPerfData(metric, nil, nil)
+	if err != nil {
+		return OptionalPerfData{}, err
+	}
+
+	return NewOptionalPerfData(perfData), nil
+}
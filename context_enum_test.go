@@ -0,0 +1,93 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func newMockEnumContext() Context {
+	degradedRange, _ := NewBoundsFromNagiosRange("2:4")
+
+	return NewEnumContext("controller", []EnumMapping{
+		NewEnumValueMapping("0", StateOk(), "optimal"),
+		NewEnumRangeMapping(degradedRange, StateWarning(), "degraded"),
+		NewEnumValueMapping("OL", StateOk(), "online"),
+		NewEnumPatternMapping(regexp.MustCompile(`^OB`), StateCritical(), "on battery"),
+	}, StateUnknown())
+}
+
+func TestEnumContext_Evaluate(t *testing.T) {
+	// given
+	context := newMockEnumContext()
+	metric1 := MustNewNumericMetric("controller", 0, "", nil, "")
+	metric2 := MustNewNumericMetric("controller", 3, "", nil, "")
+	metric3 := MustNewStringMetric("ups", "ol", "")
+	metric4 := MustNewStringMetric("ups", "OB LB", "")
+	metric5 := MustNewNumericMetric("controller", 9, "", nil, "")
+	metric6 := MustNewHistogramMetric("latency", nil, "", nil, "")
+	resource := NewResource()
+
+	// when
+	result1 := context.Evaluate(metric1, resource)
+	result2 := context.Evaluate(metric2, resource)
+	result3 := context.Evaluate(metric3, resource)
+	result4 := context.Evaluate(metric4, resource)
+	result5 := context.Evaluate(metric5, resource)
+	result6 := context.Evaluate(metric6, resource)
+
+	// then
+	assert.Equal(t, StateOk(), result1.State().OrElse(nil))
+	assert.Equal(t, StateWarning(), result2.State().OrElse(nil))
+	assert.Equal(t, StateOk(), result3.State().OrElse(nil))
+	assert.Equal(t, StateCritical(), result4.State().OrElse(nil))
+	assert.Equal(t, StateUnknown(), result5.State().OrElse(nil))
+	assert.Equal(t, StateUnknown(), result6.State().OrElse(nil))
+
+	assert.Equal(t, "controller is optimal (code 0)", result1.String())
+	assert.Equal(t, "controller is degraded (code 3)", result2.String())
+	assert.Equal(t, "ups is online (code ol)", result3.String())
+	assert.Equal(t, "ups is on battery (code OB LB)", result4.String())
+	assert.Equal(t, "controller is unmapped (code 9) (unmapped value [9])", result5.String())
+	assert.Contains(t, result6.Hint(), "EnumContext can not process metric of type")
+}
+
+func TestEnumContext_Performance(t *testing.T) {
+	// given
+	context := newMockEnumContext()
+	metric1 := MustNewNumericMetric("controller", 3, "", nil, "", MetricLabel("slot", "1"))
+	metric2 := MustNewStringMetric("status", "4", "")
+	metric3 := MustNewStringMetric("ups", "OL", "")
+	resource := NewResource()
+
+	// when
+	perfData1, err1 := context.Performance(metric1, resource)
+	perfData2, err2 := context.Performance(metric2, resource)
+	perfData3, err3 := context.Performance(metric3, resource)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, "controller=3", perfData1.OrElse(nil).ToNagiosPerfData())
+	assert.Equal(t, "status=4", perfData2.OrElse(nil).ToNagiosPerfData())
+	assert.False(t, perfData3.Present())
+}
@@ -0,0 +1,248 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistogramQuantile represents a single quantile of a HistogramMetric, which should be evaluated against the given
+// warning and/or critical threshold range and exported as performance data.
+type HistogramQuantile interface {
+	Quantile() float64
+	Label() string
+	WarningThreshold() OptionalBounds
+	CriticalThreshold() OptionalBounds
+}
+
+type histogramQuantile struct {
+	quantile          float64
+	warningThreshold  OptionalBounds
+	criticalThreshold OptionalBounds
+}
+
+type histogramContext struct {
+	baseContext
+
+	quantiles              []HistogramQuantile
+	countWarningThreshold  OptionalBounds
+	countCriticalThreshold OptionalBounds
+}
+
+// NewHistogramQuantile instantiates a new HistogramQuantile for the given quantile (0 <= q <= 1), optionally being
+// constrained by a warning and/or critical threshold range.
+func NewHistogramQuantile(quantile float64, warningThreshold *Bounds, criticalThreshold *Bounds) HistogramQuantile {
+	histogramQuantile := &histogramQuantile{
+		quantile: quantile,
+	}
+
+	if warningThreshold != nil {
+		histogramQuantile.warningThreshold = NewOptionalBounds(*warningThreshold)
+	}
+	if criticalThreshold != nil {
+		histogramQuantile.criticalThreshold = NewOptionalBounds(*criticalThreshold)
+	}
+
+	return histogramQuantile
+}
+
+// NewHistogramContext creates a new Context object, which handles metrics of the type HistogramMetric. Each of the
+// given quantiles gets evaluated against its own thresholds and exported as a separate performance data entry, while
+// the amount of observations can be constrained using the count thresholds.
+func NewHistogramContext(name string, quantiles []HistogramQuantile, countWarningThreshold *Bounds, countCriticalThreshold *Bounds, options ...ContextOpt) Context {
+	histogramContext := &histogramContext{
+		baseContext: *newBaseContext(name, "", options...),
+		quantiles:   quantiles,
+	}
+
+	if countWarningThreshold != nil {
+		histogramContext.countWarningThreshold = NewOptionalBounds(*countWarningThreshold)
+	}
+	if countCriticalThreshold != nil {
+		histogramContext.countCriticalThreshold = NewOptionalBounds(*countCriticalThreshold)
+	}
+
+	return histogramContext
+}
+
+func (c histogramContext) Describe(metric Metric) string {
+	histogramMetric, ok := metric.(HistogramMetric)
+	if !ok || c.format != "" {
+		return c.baseContext.Describe(metric)
+	}
+
+	var parts []string
+	for _, quantile := range c.quantiles {
+		value := histogramMetric.Quantile(quantile.Quantile())
+		parts = append(parts, fmt.Sprintf("%s=%s%s", quantile.Label(), formatHistogramValue(value), metric.ValueUnit()))
+	}
+	parts = append(parts, fmt.Sprintf("count=%d", histogramMetric.Count()))
+
+	return fmt.Sprintf("%s is %s", metric.Name(), strings.Join(parts, " "))
+}
+
+func (c histogramContext) Evaluate(metric Metric, resource Resource) Result {
+	histogramMetric, ok := metric.(HistogramMetric)
+	if !ok {
+		return NewResult(
+			ResultState(StateUnknown()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(fmt.Sprintf("HistogramContext can not process metric of type [%s]", reflect.TypeOf(metric))),
+		)
+	}
+
+	state := StateOk()
+	var hints []string
+
+	evaluate := func(label string, value float64, warningThreshold OptionalBounds, criticalThreshold OptionalBounds) {
+		emptyBounds := NewBounds()
+		warningBounds := warningThreshold.OrElse(emptyBounds)
+		criticalBounds := criticalThreshold.OrElse(emptyBounds)
+
+		var violationState State
+		var violationHint string
+		if !criticalBounds.Match(value) {
+			violationState, violationHint = StateCritical(), thresholdViolationHint(criticalBounds, value)
+		} else if !warningBounds.Match(value) {
+			violationState, violationHint = StateWarning(), thresholdViolationHint(warningBounds, value)
+		} else {
+			return
+		}
+
+		hints = append(hints, fmt.Sprintf("%s %s", label, violationHint))
+		if violationState.Priority() > state.Priority() {
+			state = violationState
+		}
+	}
+
+	now := c.now()
+	// quantiles of an empty histogram are undefined, so only the count thresholds can be evaluated
+	if histogramMetric.Count() > 0 {
+		for _, quantile := range c.quantiles {
+			value := histogramMetric.Quantile(quantile.Quantile())
+			evaluate(quantile.Label(), value,
+				resolveScheduledBounds(quantile.WarningThreshold(), now), resolveScheduledBounds(quantile.CriticalThreshold(), now))
+		}
+	}
+	evaluate("count", float64(histogramMetric.Count()),
+		resolveScheduledBounds(c.countWarningThreshold, now), resolveScheduledBounds(c.countCriticalThreshold, now))
+
+	return NewResult(
+		ResultState(state),
+		ResultMetric(metric), ResultContext(c), ResultResource(resource),
+		ResultHint(strings.Join(hints, ", ")),
+	)
+}
+
+func (c histogramContext) withFixedClock() Context {
+	c.clock = c.fixedClock()
+	return &c
+}
+
+func (c histogramContext) Performance(metric Metric, resource Resource) (OptionalPerfData, error) {
+	histogramMetric, ok := metric.(HistogramMetric)
+	if !ok {
+		return OptionalPerfData{}, nil
+	}
+
+	perfData, err := c.countPerformance(histogramMetric, c.now())
+	if err != nil {
+		return OptionalPerfData{}, err
+	}
+
+	return NewOptionalPerfData(perfData), nil
+}
+
+func (c histogramContext) MultiPerformance(metric Metric, resource Resource) ([]PerfData, error) {
+	histogramMetric, ok := metric.(HistogramMetric)
+	if !ok {
+		return nil, nil
+	}
+
+	var perfData []PerfData
+	now := c.now()
+	// quantiles of an empty histogram are undefined, so they are omitted just like during evaluation
+	if histogramMetric.Count() > 0 {
+		for _, quantile := range c.quantiles {
+			value := histogramMetric.Quantile(quantile.Quantile())
+			quantileMetric, err := NewNumericMetric(metric.Name()+"_"+quantile.Label(), value, metric.ValueUnit(),
+				OptionalBoundsPtr(metric.ValueRange()), metric.ContextName(), MetricLabels(metricLabels(metric)))
+			if err != nil {
+				return nil, err
+			}
+
+			quantilePerfData, err := NewPerfData(quantileMetric,
+				OptionalBoundsPtr(resolveScheduledBounds(quantile.WarningThreshold(), now)),
+				OptionalBoundsPtr(resolveScheduledBounds(quantile.CriticalThreshold(), now)))
+			if err != nil {
+				return nil, err
+			}
+
+			perfData = append(perfData, quantilePerfData)
+		}
+	}
+
+	countPerfData, err := c.countPerformance(histogramMetric, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(perfData, countPerfData), nil
+}
+
+func (c histogramContext) countPerformance(metric HistogramMetric, now time.Time) (PerfData, error) {
+	countMetric, err := NewNumericMetric(metric.Name()+"_count", float64(metric.Count()), "", nil, metric.ContextName(),
+		MetricLabels(metricLabels(metric)))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPerfData(countMetric, OptionalBoundsPtr(resolveScheduledBounds(c.countWarningThreshold, now)),
+		OptionalBoundsPtr(resolveScheduledBounds(c.countCriticalThreshold, now)))
+}
+
+func (q histogramQuantile) Quantile() float64 {
+	return q.quantile
+}
+
+func (q histogramQuantile) Label() string {
+	percentile := math.Round(q.quantile*100*1000) / 1000
+	return "p" + strconv.FormatFloat(percentile, 'f', -1, strconv.IntSize)
+}
+
+func (q histogramQuantile) WarningThreshold() OptionalBounds {
+	return q.warningThreshold
+}
+
+func (q histogramQuantile) CriticalThreshold() OptionalBounds {
+	return q.criticalThreshold
+}
+
+func formatHistogramValue(value float64) string {
+	if math.IsNaN(value) {
+		return "U"
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, strconv.IntSize)
+}
@@ -0,0 +1,166 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHistogramContext_Evaluate(t *testing.T) {
+	// given
+	p95Warning, _ := NewBoundsFromNagiosRange("30")
+	p99Critical, _ := NewBoundsFromNagiosRange("45")
+	countCritical, _ := NewBoundsFromNagiosRange("5:")
+	context := NewHistogramContext("latency", []HistogramQuantile{
+		NewHistogramQuantile(0.5, nil, nil),
+		NewHistogramQuantile(0.95, &p95Warning, nil),
+		NewHistogramQuantile(0.99, nil, &p99Critical),
+	}, nil, &countCritical)
+
+	metric1 := MustNewSampleHistogramMetric("latency", []float64{10, 20, 30, 40, 50}, "ms", nil, "")
+	metric2 := MustNewSampleHistogramMetric("latency", []float64{10, 10, 10, 10, 10}, "ms", nil, "")
+	metric3 := MustNewSampleHistogramMetric("latency", []float64{10}, "ms", nil, "")
+	metric4 := MustNewNumericMetric("invalid", 42, "", nil, "")
+	resource := NewResource()
+
+	// when
+	result1 := context.Evaluate(metric1, resource)
+	result2 := context.Evaluate(metric2, resource)
+	result3 := context.Evaluate(metric3, resource)
+	result4 := context.Evaluate(metric4, resource)
+
+	// then
+	assert.Equal(t, StateCritical(), result1.State().OrElse(nil))
+	assert.Equal(t, StateOk(), result2.State().OrElse(nil))
+	assert.Equal(t, StateCritical(), result3.State().OrElse(nil))
+	assert.Equal(t, StateUnknown(), result4.State().OrElse(nil))
+
+	assert.Equal(t, "p95 outside range 0:30, p99 outside range 0:45", result1.Hint())
+	assert.Equal(t, "", result2.Hint())
+	assert.Equal(t, "count outside range 5:+Inf", result3.Hint())
+	assert.Contains(t, result4.Hint(), "HistogramContext can not process metric of type")
+}
+
+func TestHistogramContext_Evaluate_Empty(t *testing.T) {
+	// given
+	p99Critical, _ := NewBoundsFromNagiosRange("45")
+	countWarning, _ := NewBoundsFromNagiosRange("1:")
+	context1 := NewHistogramContext("latency", []HistogramQuantile{NewHistogramQuantile(0.99, nil, nil)}, nil, nil)
+	context2 := NewHistogramContext("latency", []HistogramQuantile{
+		NewHistogramQuantile(0.99, nil, &p99Critical),
+	}, &countWarning, nil)
+	metric := MustNewSampleHistogramMetric("latency", nil, "ms", nil, "")
+	resource := NewResource()
+
+	// when
+	result1 := context1.Evaluate(metric, resource)
+	result2 := context2.Evaluate(metric, resource)
+
+	// then
+	assert.Equal(t, StateOk(), result1.State().OrElse(nil))
+	assert.Equal(t, "", result1.Hint())
+	assert.Equal(t, StateWarning(), result2.State().OrElse(nil))
+	assert.Equal(t, "count outside range 1:+Inf", result2.Hint())
+}
+
+func TestHistogramContext_Describe(t *testing.T) {
+	// given
+	context := NewHistogramContext("latency", []HistogramQuantile{
+		NewHistogramQuantile(0.5, nil, nil),
+		NewHistogramQuantile(0.999, nil, nil),
+	}, nil, nil)
+	metric1 := MustNewSampleHistogramMetric("latency", []float64{10, 20, 30}, "ms", nil, "")
+	metric2 := MustNewSampleHistogramMetric("latency", []float64{}, "ms", nil, "")
+
+	// then
+	assert.Equal(t, "latency is p50=20ms p99.9=29.98ms count=3", context.Describe(metric1))
+	assert.Equal(t, "latency is p50=Ums p99.9=Ums count=0", context.Describe(metric2))
+}
+
+func TestHistogramContext_MultiPerformance(t *testing.T) {
+	// given
+	p95Warning, _ := NewBoundsFromNagiosRange("30")
+	context := NewHistogramContext("latency", []HistogramQuantile{
+		NewHistogramQuantile(0.5, nil, nil),
+		NewHistogramQuantile(0.95, &p95Warning, nil),
+	}, nil, nil)
+	metric := MustNewSampleHistogramMetric("latency", []float64{10, 20, 30}, "ms", nil, "")
+	resource := NewResource()
+
+	// when
+	multiContext, ok := context.(MultiPerfDataContext)
+	perfData, err := multiContext.MultiPerformance(metric, resource)
+	optionalPerfData, optionalErr := context.Performance(metric, resource)
+
+	// then
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.NoError(t, optionalErr)
+	assert.Equal(t, 3, len(perfData))
+	assert.Equal(t, "latency_p50=20ms", perfData[0].ToNagiosPerfData())
+	assert.Equal(t, "latency_p95=29ms;:30", perfData[1].ToNagiosPerfData())
+	assert.Equal(t, "latency_count=3", perfData[2].ToNagiosPerfData())
+	assert.Equal(t, "latency_count=3", optionalPerfData.OrElse(nil).ToNagiosPerfData())
+}
+
+func TestHistogramContext_MultiPerformance_Empty(t *testing.T) {
+	// given
+	context := NewHistogramContext("latency", []HistogramQuantile{NewHistogramQuantile(0.99, nil, nil)}, nil, nil)
+	metric := MustNewSampleHistogramMetric("latency", nil, "ms", nil, "")
+
+	// when
+	perfData, err := context.(MultiPerfDataContext).MultiPerformance(metric, NewResource())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(perfData))
+	assert.Equal(t, "latency_count=0", perfData[0].ToNagiosPerfData())
+}
+
+func TestHistogramContext_Check(t *testing.T) {
+	// given
+	check := NewCheck("latency", NewSummarizer())
+	check.AttachResources(newMockHistogramResource())
+	check.AttachContexts(NewHistogramContext("latency", []HistogramQuantile{
+		NewHistogramQuantile(0.5, nil, nil),
+	}, nil, nil))
+
+	// when
+	result := NewRuntime(false).Execute(check)
+
+	// then
+	assert.Equal(t, "LATENCY OK - latency is p50=20ms count=3 | latency_count=3 latency_p50=20ms\n", result.Output())
+}
+
+type mockHistogramResource struct {
+	Resource
+}
+
+func newMockHistogramResource() Resource {
+	return &mockHistogramResource{
+		Resource: NewResource(),
+	}
+}
+
+func (r mockHistogramResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	return []Metric{
+		MustNewSampleHistogramMetric("latency", []float64{10, 20, 30}, "ms", nil, ""),
+	}, nil
+}
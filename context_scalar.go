@@ -32,9 +32,9 @@ type scalarContext struct {
 
 // NewScalarContext creates a new scalar Context object, which handles metrics of the type NumericMetric and provides
 // the ability to constraint these metric values to a given warning and/or critical threshold range
-func NewScalarContext(name string, warningThreshold *Bounds, criticalThreshold *Bounds) Context {
+func NewScalarContext(name string, warningThreshold *Bounds, criticalThreshold *Bounds, options ...ContextOpt) Context {
 	scalarContext := &scalarContext{
-		baseContext: *newBaseContext(name, "%<name>s is %<value>s%<unit>s"),
+		baseContext: *newBaseContext(name, "%<name>s is %<value>s%<unit>s", options...),
 	}
 
 	if warningThreshold != nil {
@@ -58,20 +58,21 @@ func (c scalarContext) Evaluate(metric Metric, resource Resource) Result {
 	}
 
 	emptyBounds := NewBounds()
-	warningThreshold := c.warningThreshold.OrElse(emptyBounds)
-	criticalThreshold := c.criticalThreshold.OrElse(emptyBounds)
+	optionalWarningThreshold, optionalCriticalThreshold := c.resolveThresholds(metric)
+	warningThreshold := optionalWarningThreshold.OrElse(emptyBounds)
+	criticalThreshold := optionalCriticalThreshold.OrElse(emptyBounds)
 
 	if !criticalThreshold.Match(numericMetric.Value()) {
 		return NewResult(
 			ResultState(StateCritical()),
 			ResultMetric(metric), ResultContext(c), ResultResource(resource),
-			ResultHint(criticalThreshold.ViolationHint()),
+			ResultHint(thresholdViolationHint(criticalThreshold, numericMetric.Value())),
 		)
 	} else if !warningThreshold.Match(numericMetric.Value()) {
 		return NewResult(
 			ResultState(StateWarning()),
 			ResultMetric(metric), ResultContext(c), ResultResource(resource),
-			ResultHint(warningThreshold.ViolationHint()),
+			ResultHint(thresholdViolationHint(warningThreshold, numericMetric.Value())),
 		)
 	}
 
@@ -81,11 +82,29 @@ func (c scalarContext) Evaluate(metric Metric, resource Resource) Result {
 	)
 }
 
+func (c scalarContext) withFixedClock() Context {
+	c.clock = c.fixedClock()
+	return &c
+}
+
 func (c scalarContext) Performance(metric Metric, resource Resource) (OptionalPerfData, error) {
-	perfData, err := NewPerfData(metric, OptionalBoundsPtr(c.warningThreshold), OptionalBoundsPtr(c.criticalThreshold))
+	warningThreshold, criticalThreshold := c.resolveThresholds(metric)
+	perfData, err := NewPerfData(metric, OptionalBoundsPtr(warningThreshold), OptionalBoundsPtr(criticalThreshold))
 	if err != nil {
 		return OptionalPerfData{}, err
 	}
 
 	return NewOptionalPerfData(perfData), nil
 }
+
+func (c scalarContext) resolveThresholds(metric Metric) (OptionalBounds, OptionalBounds) {
+	warningThreshold, criticalThreshold := c.warningThreshold, c.criticalThreshold
+	if c.thresholdMap != nil {
+		if mappedWarningThreshold, mappedCriticalThreshold, ok := c.thresholdMap.Resolve(metric); ok {
+			warningThreshold, criticalThreshold = mappedWarningThreshold, mappedCriticalThreshold
+		}
+	}
+
+	now := c.now()
+	return resolveScheduledBounds(warningThreshold, now), resolveScheduledBounds(criticalThreshold, now)
+}
@@ -21,6 +21,7 @@ package nagopher
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestScalarContext_Evaluate(t *testing.T) {
@@ -78,3 +79,87 @@ func TestScalarContext_Performance(t *testing.T) {
 	assert.Implements(t, (*PerfData)(nil), perfData1)
 	assert.Nil(t, perfData2)
 }
+
+func TestScalarContext_ThresholdMap(t *testing.T) {
+	// given
+	defaultThreshold, _ := NewBoundsFromNagiosRange("90")
+	thresholdMap, _ := ParseThresholdMap("/var=40:50", ThresholdSubjectLabel("mount"))
+	context := NewScalarContext("disk", nil, &defaultThreshold, ContextThresholdMap(thresholdMap))
+	metric1 := MustNewNumericMetric("{mount}_used", 60, "%", nil, "disk", MetricLabel("mount", "/var"))
+	metric2 := MustNewNumericMetric("{mount}_used", 60, "%", nil, "disk", MetricLabel("mount", "/tmp"))
+	resource := NewResource()
+
+	// when
+	result1 := context.Evaluate(metric1, resource)
+	result2 := context.Evaluate(metric2, resource)
+	perfData1, _ := context.Performance(metric1, resource)
+	perfData2, _ := context.Performance(metric2, resource)
+
+	// then
+	assert.Equal(t, StateCritical(), result1.State().OrElse(nil))
+	assert.Equal(t, "outside range 0:50", result1.Hint())
+	assert.Equal(t, StateOk(), result2.State().OrElse(nil))
+	assert.Equal(t, "'/var_used'=60%;:40;:50", perfData1.OrElse(nil).ToNagiosPerfData())
+	assert.Equal(t, "'/tmp_used'=60%;;:90", perfData2.OrElse(nil).ToNagiosPerfData())
+}
+
+func TestScalarContext_ScheduledBounds(t *testing.T) {
+	// given
+	now := time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC)
+	nightWindow, _ := ParseWeeklyWindow("22:00-06:00", time.UTC)
+	dayThreshold, _ := NewBoundsFromNagiosRange("10")
+	nightThreshold, _ := NewBoundsFromNagiosRange("50")
+	criticalThreshold := Bounds(NewScheduledBounds(dayThreshold, BoundsDuring(nightWindow, nightThreshold)))
+	context := NewScalarContext("load", nil, &criticalThreshold, ContextClock(func() time.Time { return now }))
+	metric := MustNewNumericMetric("load", 20, "", nil, "")
+	resource := NewResource()
+
+	// when
+	result1 := context.Evaluate(metric, resource)
+	perfData1, _ := context.Performance(metric, resource)
+	now = now.Add(11 * time.Hour)
+	result2 := context.Evaluate(metric, resource)
+	perfData2, _ := context.Performance(metric, resource)
+
+	// then
+	assert.Equal(t, StateCritical(), result1.State().OrElse(nil))
+	assert.Equal(t, "outside range 0:10", result1.Hint())
+	assert.Equal(t, "load=20;;:10", perfData1.OrElse(nil).ToNagiosPerfData())
+	assert.Equal(t, StateOk(), result2.State().OrElse(nil))
+	assert.Equal(t, "load=20;;:50", perfData2.OrElse(nil).ToNagiosPerfData())
+}
+
+func TestScalarContext_ScheduledBounds_Check(t *testing.T) {
+	// given
+	now := time.Date(2019, 6, 21, 5, 59, 0, 0, time.UTC)
+	clock := func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	nightWindow, _ := ParseWeeklyWindow("22:00-06:00", time.UTC)
+	dayThreshold, _ := NewBoundsFromNagiosRange("10")
+	nightThreshold, _ := NewBoundsFromNagiosRange("50")
+	criticalThreshold := Bounds(NewScheduledBounds(dayThreshold, BoundsDuring(nightWindow, nightThreshold)))
+
+	check := NewCheck("load", NewSummarizer())
+	check.AttachResources(&mockLoadResource{Resource: NewResource()})
+	check.AttachContexts(NewScalarContext("load", nil, &criticalThreshold, ContextClock(clock)))
+
+	// when
+	now = now.Add(-time.Minute)
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateOk(), check.State())
+	assert.Equal(t, 1, len(check.PerfData()))
+	assert.Equal(t, "load=20;;:50", check.PerfData()[0].ToNagiosPerfData())
+}
+
+type mockLoadResource struct {
+	Resource
+}
+
+func (r mockLoadResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	return []Metric{MustNewNumericMetric("load", 20, "", nil, "")}, nil
+}
@@ -0,0 +1,177 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type setContext struct {
+	baseContext
+
+	expectedMembers []string
+	missingState    State
+	unexpectedState State
+}
+
+type setDifference struct {
+	present    []string
+	missing    []string
+	unexpected []string
+}
+
+// NewSetContext creates a new Context object, which compares the members of a SetMetric against the given expected
+// members. Missing members result in the missing state, while members not being expected result in the unexpected
+// state, which can be StateOk() to tolerate additional members. Besides the usual placeholders, the format may contain
+// %<present>s, %<missing>s, %<unexpected>s and %<expected>s, which contain the amount of according members. The counts
+// of present, missing and unexpected members get exported as performance data.
+func NewSetContext(name string, expectedMembers []string, missingState State, unexpectedState State, options ...ContextOpt) Context {
+	setContext := &setContext{
+		baseContext:     *newBaseContext(name, "%<name>s has %<present>s of %<expected>s expected members", options...),
+		expectedMembers: uniqueSortedStrings(expectedMembers),
+		missingState:    missingState,
+		unexpectedState: unexpectedState,
+	}
+
+	return setContext
+}
+
+func (c setContext) compare(metric SetMetric) setDifference {
+	var difference setDifference
+	expected := make(map[string]bool)
+
+	for _, member := range c.expectedMembers {
+		expected[member] = true
+		if metric.Contains(member) {
+			difference.present = append(difference.present, member)
+		} else {
+			difference.missing = append(difference.missing, member)
+		}
+	}
+
+	for _, member := range metric.Value() {
+		if !expected[member] {
+			difference.unexpected = append(difference.unexpected, member)
+		}
+	}
+
+	return difference
+}
+
+func (c setContext) Describe(metric Metric) string {
+	setMetric, ok := metric.(SetMetric)
+	if !ok {
+		return c.baseContext.Describe(metric)
+	}
+
+	difference := c.compare(setMetric)
+	return c.describe(metric, map[string]interface{}{
+		"present":    strconv.Itoa(len(difference.present)),
+		"missing":    strconv.Itoa(len(difference.missing)),
+		"unexpected": strconv.Itoa(len(difference.unexpected)),
+		"expected":   strconv.Itoa(len(c.expectedMembers)),
+	})
+}
+
+func (c setContext) Evaluate(metric Metric, resource Resource) Result {
+	setMetric, ok := metric.(SetMetric)
+	if !ok {
+		return NewResult(
+			ResultState(StateUnknown()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(fmt.Sprintf("SetContext can not process metric of type [%s]", reflect.TypeOf(metric))),
+		)
+	}
+
+	difference := c.compare(setMetric)
+	state := StateOk()
+	var hints []string
+
+	if len(difference.missing) > 0 {
+		state = c.missingState
+		hints = append(hints, fmt.Sprintf("missing [%s]", strings.Join(difference.missing, "],[")))
+	}
+	if len(difference.unexpected) > 0 {
+		if c.unexpectedState.Priority() > state.Priority() {
+			state = c.unexpectedState
+		}
+		hints = append(hints, fmt.Sprintf("unexpected [%s]", strings.Join(difference.unexpected, "],[")))
+	}
+
+	return NewResult(
+		ResultState(state),
+		ResultMetric(metric), ResultContext(c), ResultResource(resource),
+		ResultHint(strings.Join(hints, ", ")),
+	)
+}
+
+func (c setContext) Performance(metric Metric, resource Resource) (OptionalPerfData, error) {
+	setMetric, ok := metric.(SetMetric)
+	if !ok {
+		return OptionalPerfData{}, nil
+	}
+
+	perfData, err := c.countPerformance(setMetric, "present", len(c.compare(setMetric).present))
+	if err != nil {
+		return OptionalPerfData{}, err
+	}
+
+	return NewOptionalPerfData(perfData), nil
+}
+
+func (c setContext) MultiPerformance(metric Metric, resource Resource) ([]PerfData, error) {
+	setMetric, ok := metric.(SetMetric)
+	if !ok {
+		return nil, nil
+	}
+
+	difference := c.compare(setMetric)
+	counts := []struct {
+		suffix string
+		count  int
+	}{
+		{"present", len(difference.present)},
+		{"missing", len(difference.missing)},
+		{"unexpected", len(difference.unexpected)},
+	}
+
+	var perfData []PerfData
+	for _, count := range counts {
+		countPerfData, err := c.countPerformance(setMetric, count.suffix, count.count)
+		if err != nil {
+			return nil, err
+		}
+		perfData = append(perfData, countPerfData)
+	}
+
+	return perfData, nil
+}
+
+func (c setContext) countPerformance(metric SetMetric, suffix string, count int) (PerfData, error) {
+	countMetric, err := NewNumericMetric(metric.Name()+"_"+suffix, float64(count), "", nil, metric.ContextName(),
+		MetricLabels(metricLabels(metric)))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPerfData(countMetric, nil, nil)
+}
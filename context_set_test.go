@@ -0,0 +1,74 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetContext_Evaluate(t *testing.T) {
+	// given
+	context1 := NewSetContext("units", []string{"sshd", "cron", "nginx"}, StateCritical(), StateWarning())
+	context2 := NewSetContext("units", []string{"sshd", "cron", "nginx"}, StateWarning(), StateOk())
+	metric1 := MustNewSetMetric("units", []string{"nginx", "sshd", "cron"}, "")
+	metric2 := MustNewSetMetric("units", []string{"sshd", "postfix", "sshd"}, "")
+	metric3 := MustNewSetMetric("units", []string{"sshd", "cron", "nginx", "postfix"}, "")
+	metric4 := MustNewStringMetric("units", "sshd", "")
+	resource := NewResource()
+
+	// when
+	result1 := context1.Evaluate(metric1, resource)
+	result2 := context1.Evaluate(metric2, resource)
+	result3 := context1.Evaluate(metric3, resource)
+	result4 := context2.Evaluate(metric2, resource)
+	result5 := context2.Evaluate(metric3, resource)
+	result6 := context1.Evaluate(metric4, resource)
+
+	// then
+	assert.Equal(t, StateOk(), result1.State().OrElse(nil))
+	assert.Equal(t, StateCritical(), result2.State().OrElse(nil))
+	assert.Equal(t, StateWarning(), result3.State().OrElse(nil))
+	assert.Equal(t, StateWarning(), result4.State().OrElse(nil))
+	assert.Equal(t, StateOk(), result5.State().OrElse(nil))
+	assert.Equal(t, StateUnknown(), result6.State().OrElse(nil))
+
+	assert.Equal(t, "units has 3 of 3 expected members", result1.String())
+	assert.Equal(t, "units has 1 of 3 expected members (missing [cron],[nginx], unexpected [postfix])", result2.String())
+	assert.Equal(t, "unexpected [postfix]", result3.Hint())
+	assert.Contains(t, result6.Hint(), "SetContext can not process metric of type")
+}
+
+func TestSetContext_Performance(t *testing.T) {
+	// given
+	context := NewSetContext("units", []string{"sshd", "cron", "nginx"}, StateCritical(), StateWarning())
+	metric := MustNewSetMetric("units", []string{"sshd", "postfix"}, "")
+	check := NewCheck("check", NewSummarizer())
+	check.AttachResources(&mockMetricsResource{NewResource(), []Metric{metric}})
+	check.AttachContexts(context)
+
+	// when
+	perfData, err := context.Performance(metric, NewResource())
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "units_present=1", perfData.OrElse(nil).ToNagiosPerfData())
+	assert.Equal(t, []string{"units_missing=2", "units_present=1", "units_unexpected=1"}, perfDataStrings(check.PerfData()))
+}
@@ -36,9 +36,9 @@ type stringMatchContext struct {
 }
 
 // NewStringInfoContext instantiates a Context which only holds and returns a plain string without any further logic.
-func NewStringInfoContext(name string) Context {
+func NewStringInfoContext(name string, options ...ContextOpt) Context {
 	stringInfoContext := &stringInfoContext{
-		baseContext: *newBaseContext(name, "%<value>s"),
+		baseContext: *newBaseContext(name, "%<value>s", options...),
 	}
 
 	return stringInfoContext
@@ -53,9 +53,9 @@ func (c stringInfoContext) Evaluate(metric Metric, resource Resource) Result {
 
 // NewStringMatchContext instantiates a Context which holds a string, which is being compared to a whitelist of
 // acceptable values during the evaluation phase. Should the value not be accepted, a problem state gets returned.
-func NewStringMatchContext(name string, problemState State, expectedValues []string) Context {
+func NewStringMatchContext(name string, problemState State, expectedValues []string, options ...ContextOpt) Context {
 	stringContext := &stringMatchContext{
-		baseContext: *newBaseContext(name, "%<name>s is %<value>s"),
+		baseContext: *newBaseContext(name, "%<name>s is %<value>s", options...),
 
 		problemState:   problemState,
 		expectedValues: stringsToLower(expectedValues),
@@ -73,3 +73,30 @@ func TestBaseContext_Performance(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, OptionalPerfData{}, perfData)
 }
+
+func TestBaseContext_Describe_Labels(t *testing.T) {
+	// given
+	metric := MustNewNumericMetric("{mount}_used", 42, "%", nil, "disk", MetricLabel("mount", "/var"))
+	context1 := NewBaseContext("disk", "%<name>s is %<value>s%<unit>s")
+	context2 := NewBaseContext("disk", "{mount} is %<value>s%<unit>s full")
+	context3 := NewScalarContext("disk", nil, nil, ContextFormat("%<mount>s uses %<value>s%<unit>s"))
+
+	// then
+	assert.Equal(t, "/var_used is 42%", context1.Describe(metric))
+	assert.Equal(t, "/var is 42% full", context2.Describe(metric))
+	assert.Equal(t, "/var uses 42%", context3.Describe(metric))
+}
+
+func TestBaseContext_LabelSelector(t *testing.T) {
+	// given
+	selector := Labels{"mount": "/var"}
+	context1 := NewBaseContext("disk", "")
+	context2 := NewBaseContext("disk", "", ContextLabelSelector(selector))
+
+	// when
+	selector["mount"] = "/tmp"
+
+	// then
+	assert.Empty(t, contextLabelSelector(context1))
+	assert.Equal(t, Labels{"mount": "/var"}, contextLabelSelector(context2))
+}
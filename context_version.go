@@ -0,0 +1,89 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type versionContext struct {
+	baseContext
+
+	warningConstraint  VersionConstraint
+	criticalConstraint VersionConstraint
+}
+
+// NewVersionContext creates a new Context object, which parses the value of a StringMetric as Version and compares it
+// against the given warning and/or critical constraint. Constraints describe the problematic versions, so that e.g.
+// "<1.1.1k" flags all versions older than the given one and ">=2.0 <2.0.3" flags a range of known broken releases.
+func NewVersionContext(name string, warningConstraint VersionConstraint, criticalConstraint VersionConstraint, options ...ContextOpt) Context {
+	versionContext := &versionContext{
+		baseContext:        *newBaseContext(name, "%<name>s is %<value>s", options...),
+		warningConstraint:  warningConstraint,
+		criticalConstraint: criticalConstraint,
+	}
+
+	return versionContext
+}
+
+func (c versionContext) Evaluate(metric Metric, resource Resource) Result {
+	stringMetric, ok := metric.(StringMetric)
+	if !ok {
+		return NewResult(
+			ResultState(StateUnknown()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(fmt.Sprintf("VersionContext can not process metric of type [%s]", reflect.TypeOf(metric))),
+		)
+	}
+
+	version, err := ParseVersion(stringMetric.Value())
+	if err != nil {
+		return NewResult(
+			ResultState(StateUnknown()),
+			ResultMetric(metric), ResultContext(c), ResultResource(resource),
+			ResultHint(err.Error()),
+		)
+	}
+
+	if c.criticalConstraint != nil {
+		if c.criticalConstraint.Matches(version) {
+			return NewResult(
+				ResultState(StateCritical()),
+				ResultMetric(metric), ResultContext(c), ResultResource(resource),
+				ResultHint(fmt.Sprintf("matches constraint [%s]", c.criticalConstraint)),
+			)
+		}
+	}
+
+	if c.warningConstraint != nil {
+		if c.warningConstraint.Matches(version) {
+			return NewResult(
+				ResultState(StateWarning()),
+				ResultMetric(metric), ResultContext(c), ResultResource(resource),
+				ResultHint(fmt.Sprintf("matches constraint [%s]", c.warningConstraint)),
+			)
+		}
+	}
+
+	return NewResult(
+		ResultState(StateOk()),
+		ResultMetric(metric), ResultContext(c), ResultResource(resource),
+	)
+}
@@ -0,0 +1,51 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVersionContext_Evaluate(t *testing.T) {
+	// given
+	warningConstraint, _ := ParseVersionConstraint("<1.1.1k")
+	criticalConstraint, _ := ParseVersionConstraint("=1.1.1f")
+	context := NewVersionContext("openssl", warningConstraint, criticalConstraint)
+	resource := NewResource()
+
+	// when
+	result1 := context.Evaluate(MustNewStringMetric("openssl", "1.1.1k", ""), resource)
+	result2 := context.Evaluate(MustNewStringMetric("openssl", "1.1.1j", ""), resource)
+	result3 := context.Evaluate(MustNewStringMetric("openssl", "1.1.1f", ""), resource)
+	result4 := context.Evaluate(MustNewStringMetric("openssl", "unknown", ""), resource)
+	result5 := context.Evaluate(MustNewNumericMetric("openssl", 1, "", nil, ""), resource)
+
+	// then
+	assert.Equal(t, StateOk(), result1.State().OrElse(nil))
+	assert.Equal(t, StateWarning(), result2.State().OrElse(nil))
+	assert.Equal(t, StateCritical(), result3.State().OrElse(nil))
+	assert.Equal(t, StateUnknown(), result4.State().OrElse(nil))
+	assert.Equal(t, StateUnknown(), result5.State().OrElse(nil))
+
+	assert.Equal(t, "openssl is 1.1.1j (matches constraint [<1.1.1k])", result2.String())
+	assert.Equal(t, "openssl is 1.1.1f (matches constraint [=1.1.1f])", result3.String())
+	assert.Equal(t, "version [unknown] must start with a digit", result4.Hint())
+	assert.Contains(t, result5.Hint(), "VersionContext can not process metric of type")
+}
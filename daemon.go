@@ -0,0 +1,324 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CheckFactory creates a new Check instance for each scheduled execution. Long-living state like connection pools or
+// caches should be captured by the factory and shared between the created checks.
+type CheckFactory func() Check
+
+// Daemon periodically executes registered checks in the background and caches their latest results, which can be
+// queried over a local HTTP API either as plain Nagios plugin output or as JSON. This avoids paying the startup costs
+// of a plugin for every single check execution.
+type Daemon interface {
+	http.Handler
+
+	Register(name string, interval time.Duration, factory CheckFactory)
+	Start()
+	Stop()
+	ListenAndServe(address string) error
+}
+
+// DaemonOpt is a type alias for functional options used by NewDaemon()
+type DaemonOpt func(*daemon)
+
+type daemon struct {
+	runtime Runtime
+	jitter  time.Duration
+	after   func(time.Duration) <-chan time.Time
+
+	mutex   sync.RWMutex
+	jobs    map[string]*daemonJob
+	results map[string]*daemonResult
+	running bool
+	wg      sync.WaitGroup
+	server  *http.Server
+}
+
+type daemonJob struct {
+	name     string
+	interval time.Duration
+	factory  CheckFactory
+	stop     chan struct{}
+}
+
+type daemonResult struct {
+	result  CheckResult
+	report  checkReport
+	updated time.Time
+}
+
+// NewDaemon instantiates a new Daemon with the given functional options
+func NewDaemon(options ...DaemonOpt) Daemon {
+	daemon := &daemon{
+		runtime: NewRuntime(false),
+		after:   time.After,
+		jobs:    make(map[string]*daemonJob),
+		results: make(map[string]*daemonResult),
+	}
+
+	for _, option := range options {
+		option(daemon)
+	}
+
+	return daemon
+}
+
+// DaemonRuntime is a functional option for NewDaemon(), which sets the runtime used for executing checks
+func DaemonRuntime(runtime Runtime) DaemonOpt {
+	return func(d *daemon) {
+		d.runtime = runtime
+	}
+}
+
+// DaemonJitter is a functional option for NewDaemon(), which delays each check execution by a random duration up to the
+// given maximum. This spreads the load of checks sharing the same interval.
+func DaemonJitter(jitter time.Duration) DaemonOpt {
+	return func(d *daemon) {
+		d.jitter = jitter
+	}
+}
+
+func (d *daemon) Register(name string, interval time.Duration, factory CheckFactory) {
+	job := &daemonJob{name: name, interval: interval, factory: factory}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if previousJob, ok := d.jobs[name]; ok && d.running {
+		close(previousJob.stop)
+	}
+
+	d.jobs[name] = job
+	if d.running {
+		d.schedule(job)
+	}
+}
+
+func (d *daemon) Start() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.running {
+		return
+	}
+
+	d.running = true
+	for _, job := range d.jobs {
+		d.schedule(job)
+	}
+}
+
+func (d *daemon) Stop() {
+	d.mutex.Lock()
+	if !d.running {
+		d.mutex.Unlock()
+		return
+	}
+
+	d.running = false
+	for _, job := range d.jobs {
+		close(job.stop)
+	}
+	server := d.server
+	d.mutex.Unlock()
+
+	if server != nil {
+		_ = server.Shutdown(context.Background())
+	}
+	d.wg.Wait()
+}
+
+func (d *daemon) ListenAndServe(address string) error {
+	d.mutex.Lock()
+	d.server = &http.Server{Addr: address, Handler: d}
+	server := d.server
+	d.mutex.Unlock()
+
+	d.Start()
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		d.Stop()
+		return err
+	}
+
+	return nil
+}
+
+// schedule starts a goroutine periodically executing the given job until its stop channel gets closed. Each job owns a
+// separate channel, so that re-registering a job only stops the goroutine of the replaced job.
+func (d *daemon) schedule(job *daemonJob) {
+	job.stop = make(chan struct{})
+	stop := job.stop
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		delay := d.jitterDuration()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-d.after(delay):
+			}
+
+			d.execute(job)
+			delay = job.interval + d.jitterDuration()
+		}
+	}()
+}
+
+func (d *daemon) jitterDuration() time.Duration {
+	if d.jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d.jitter)))
+}
+
+// execute runs the given job and caches its result, unless the job has been replaced in the meantime. This prevents a
+// slow execution of a replaced job from overwriting the result of its successor.
+func (d *daemon) execute(job *daemonJob) {
+	check, result := d.executeFactory(job)
+	cachedResult := &daemonResult{
+		result:  result,
+		report:  newCheckReport(check, result),
+		updated: time.Now(),
+	}
+
+	d.mutex.Lock()
+	if d.jobs[job.name] == job {
+		d.results[job.name] = cachedResult
+	}
+	d.mutex.Unlock()
+}
+
+// executeFactory creates a new check using the factory of the given job and executes it. A panicking factory results in
+// an empty check together with an UNKNOWN result, just like a check which panicked during its execution.
+func (d *daemon) executeFactory(job *daemonJob) (check Check, result CheckResult) {
+	defer func() {
+		if value := recover(); value != nil {
+			check = NewCheck(job.name, NewSummarizer())
+			result = newFactoryPanicResult(job.name, value)
+		}
+	}()
+
+	check = job.factory()
+	return check, d.runtime.Execute(check)
+}
+
+func newFactoryPanicResult(name string, value interface{}) CheckResult {
+	var outputParts []string
+	if name != "" {
+		outputParts = append(outputParts, strings.ToUpper(name))
+	}
+	outputParts = append(outputParts, strings.ToUpper(StateUnknown().Description()), "-",
+		fmt.Sprintf("nagopher: check factory panicked with [%v]", value))
+
+	output := strings.Join(baseRuntime{}.sanitizeStrings(outputParts, nil), " ") + "\n"
+	return NewCheckResult(StateUnknown().ExitCode(), output)
+}
+
+func (d *daemon) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Trim(request.URL.Path, "/")
+	if path == "checks" {
+		d.serveIndex(writer)
+		return
+	}
+
+	if !strings.HasPrefix(path, "checks/") {
+		http.NotFound(writer, request)
+		return
+	}
+
+	name := strings.TrimPrefix(path, "checks/")
+	asJSON := request.URL.Query().Get("format") == "json" || strings.Contains(request.Header.Get("Accept"), "application/json")
+	if strings.HasSuffix(name, ".json") {
+		name, asJSON = strings.TrimSuffix(name, ".json"), true
+	}
+
+	d.mutex.RLock()
+	_, registered := d.jobs[name]
+	cachedResult := d.results[name]
+	d.mutex.RUnlock()
+
+	if !registered {
+		http.Error(writer, fmt.Sprintf("check [%s] is not registered", name), http.StatusNotFound)
+		return
+	} else if cachedResult == nil {
+		http.Error(writer, fmt.Sprintf("check [%s] has not been executed yet", name), http.StatusServiceUnavailable)
+		return
+	}
+
+	writer.Header().Set(daemonExitCodeHeader, fmt.Sprintf("%d", cachedResult.result.ExitCode()))
+	writer.Header().Set("Last-Modified", cachedResult.updated.UTC().Format(http.TimeFormat))
+
+	if asJSON {
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(cachedResult.report)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = writer.Write([]byte(cachedResult.result.Output()))
+}
+
+func (d *daemon) serveIndex(writer http.ResponseWriter) {
+	type indexEntry struct {
+		Name     string    `json:"name"`
+		Interval string    `json:"interval"`
+		State    string    `json:"state,omitempty"`
+		Updated  time.Time `json:"updated,omitempty"`
+	}
+
+	d.mutex.RLock()
+	entries := make([]indexEntry, 0, len(d.jobs))
+	for name, job := range d.jobs {
+		entry := indexEntry{Name: name, Interval: job.interval.String()}
+		if cachedResult, ok := d.results[name]; ok {
+			entry.State = cachedResult.report.State
+			entry.Updated = cachedResult.updated
+		}
+		entries = append(entries, entry)
+	}
+	d.mutex.RUnlock()
+
+	sort.Slice(entries, func(a int, b int) bool {
+		return entries[a].Name < entries[b].Name
+	})
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(entries)
+}
@@ -0,0 +1,73 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const daemonExitCodeHeader = "X-Nagopher-Exit-Code"
+
+// FetchCheckResult retrieves the latest cached result of the given check from a Daemon listening at the base URL,
+// e.g. "http://127.0.0.1:5666". This allows thin plugin executables, which only forward the cached result to Nagios.
+func FetchCheckResult(baseURL string, name string, timeout time.Duration) (CheckResult, error) {
+	client := &http.Client{Timeout: timeout}
+	checkURL := strings.TrimRight(baseURL, "/") + "/checks/" + url.PathEscape(name)
+
+	response, err := client.Get(checkURL)
+	if err != nil {
+		return nil, fmt.Errorf("nagopher: could not fetch check result (%s)", err.Error())
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("nagopher: could not read check result (%s)", err.Error())
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nagopher: daemon returned status [%d] (%s)", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	exitCode, err := strconv.ParseInt(response.Header.Get(daemonExitCodeHeader), 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("nagopher: daemon returned invalid exit code [%s]", response.Header.Get(daemonExitCodeHeader))
+	}
+
+	return NewCheckResult(int8(exitCode), string(body)), nil
+}
+
+// FetchCheckResultAndExit calls FetchCheckResult, prints the output and exits with the according exit code. Should the
+// result not be retrievable, an UNKNOWN state is returned instead.
+func FetchCheckResultAndExit(baseURL string, name string, timeout time.Duration) {
+	result, err := FetchCheckResult(baseURL, name, timeout)
+	if err != nil {
+		result = NewCheckResult(StateUnknown().ExitCode(), fmt.Sprintf("%s %s - %s\n",
+			strings.ToUpper(name), strings.ToUpper(StateUnknown().Description()), err.Error()))
+	}
+
+	_, _ = resultOutputFunction(result.Output())
+	resultExitFunction(int(result.ExitCode()))
+}
@@ -0,0 +1,292 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDaemon_Execute(t *testing.T) {
+	// given
+	var executions int32
+	daemon, timers := newMockDaemon(DaemonJitter(time.Millisecond))
+	daemon.Register("usage", 10*time.Millisecond, func() Check {
+		atomic.AddInt32(&executions, 1)
+		return newMockDaemonCheck()
+	})
+
+	server := httptest.NewServer(daemon)
+	defer server.Close()
+
+	// when
+	daemon.Start()
+	daemon.Start()
+	fireDaemonTimer(timers)
+	fireDaemonTimer(timers)
+	<-timers
+	daemon.Stop()
+	daemon.Stop()
+
+	result, err := FetchCheckResult(server.URL, "usage", time.Second)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&executions))
+	assert.Equal(t, StateWarning().ExitCode(), result.ExitCode())
+	assert.Equal(t, NewRuntime(false).Execute(newMockDaemonCheck()).Output(), result.Output())
+}
+
+func TestDaemon_Execute_FactoryPanic(t *testing.T) {
+	// given
+	daemon, timers := newMockDaemon()
+	daemon.Register("usage", time.Hour, func() Check {
+		panic("connection pool exhausted")
+	})
+
+	server := httptest.NewServer(daemon)
+	defer server.Close()
+
+	// when
+	daemon.Start()
+	fireDaemonTimer(timers)
+	<-timers
+	daemon.Stop()
+
+	result, err := FetchCheckResult(server.URL, "usage", time.Second)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
+	assert.Equal(t, "USAGE UNKNOWN - nagopher: check factory panicked with [connection pool exhausted]\n", result.Output())
+}
+
+func TestDaemon_Register_Replace(t *testing.T) {
+	// given
+	var executions1, executions2 int32
+	daemon, timers := newMockDaemon()
+	daemon.Register("usage", 5*time.Millisecond, func() Check {
+		atomic.AddInt32(&executions1, 1)
+		return newMockDaemonCheck()
+	})
+
+	// when
+	daemon.Start()
+	fireDaemonTimer(timers)
+	<-timers
+	daemon.Register("usage", 5*time.Millisecond, func() Check {
+		atomic.AddInt32(&executions2, 1)
+		return newMockDaemonCheck()
+	})
+	fireDaemonTimer(timers)
+	fireDaemonTimer(timers)
+	<-timers
+	daemon.Stop()
+
+	// then
+	assert.Equal(t, int32(1), atomic.LoadInt32(&executions1))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&executions2))
+}
+
+func TestDaemon_Register_ReplaceDuringExecution(t *testing.T) {
+	// given
+	daemon, _ := newMockDaemon()
+	daemon.Register("usage", time.Hour, newMockDaemonCheck)
+	replacedJob := daemon.jobs["usage"]
+	daemon.Register("usage", time.Hour, newMockDaemonCheck)
+
+	// when
+	daemon.execute(replacedJob)
+
+	// then
+	assert.Nil(t, daemon.results["usage"])
+}
+
+func TestDaemon_ListenAndServe_Error(t *testing.T) {
+	// given
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	daemon, _ := newMockDaemon()
+	daemon.Register("usage", time.Hour, newMockDaemonCheck)
+
+	// when
+	err = daemon.ListenAndServe(listener.Addr().String())
+
+	// then
+	assert.Error(t, err)
+	assert.False(t, daemon.running)
+}
+
+func TestDaemon_ServeHTTP_JSON(t *testing.T) {
+	// given
+	daemon, timers := newMockDaemon()
+	daemon.Register("usage", time.Hour, newMockDaemonCheck)
+	server := httptest.NewServer(daemon)
+	defer server.Close()
+
+	daemon.Start()
+	fireDaemonTimer(timers)
+	<-timers
+	daemon.Stop()
+
+	// when
+	var report checkReport
+	response, err := http.Get(server.URL + "/checks/usage.json")
+	if err == nil {
+		defer response.Body.Close()
+		err = json.NewDecoder(response.Body).Decode(&report)
+	}
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "usage", report.Name)
+	assert.Equal(t, "warning", report.State)
+	assert.Equal(t, int8(1), report.ExitCode)
+	assert.Equal(t, []string{"usage1=49.4%;10:80", "usage2=92.6%;10:80"}, report.PerfData)
+	assert.Equal(t, 2, len(report.Results))
+	assert.Equal(t, "warning", report.Results[0].State)
+	assert.Equal(t, "usage2 is 92.6% (outside range 10:80)", report.Results[0].Text)
+	assert.Equal(t, "usage", report.Results[0].Context)
+	assert.Equal(t, "*nagopher.mockDaemonResource", report.Results[0].Resource)
+}
+
+func TestDaemon_ServeHTTP_Errors(t *testing.T) {
+	// given
+	daemon := NewDaemon()
+	daemon.Register("pending", time.Hour, newMockDaemonCheck)
+	server := httptest.NewServer(daemon)
+	defer server.Close()
+
+	// when
+	response1, err1 := http.Get(server.URL + "/checks/missing")
+	response2, err2 := http.Get(server.URL + "/checks/pending")
+	response3, err3 := http.Post(server.URL+"/checks/pending", "text/plain", nil)
+	response4, err4 := http.Get(server.URL + "/unknown")
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.NoError(t, err4)
+	assert.Equal(t, http.StatusNotFound, response1.StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, response2.StatusCode)
+	assert.Equal(t, http.StatusMethodNotAllowed, response3.StatusCode)
+	assert.Equal(t, http.StatusNotFound, response4.StatusCode)
+}
+
+func TestDaemon_ServeHTTP_Index(t *testing.T) {
+	// given
+	daemon := NewDaemon()
+	daemon.Register("b", time.Hour, newMockDaemonCheck)
+	daemon.Register("a", time.Minute, newMockDaemonCheck)
+	server := httptest.NewServer(daemon)
+	defer server.Close()
+
+	// when
+	var entries []map[string]interface{}
+	response, err := http.Get(server.URL + "/checks")
+	if err == nil {
+		defer response.Body.Close()
+		err = json.NewDecoder(response.Body).Decode(&entries)
+	}
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "a", entries[0]["name"])
+	assert.Equal(t, "1m0s", entries[0]["interval"])
+	assert.Equal(t, "b", entries[1]["name"])
+}
+
+func TestFetchCheckResultAndExit(t *testing.T) {
+	var resultExitCode = -1
+	var resultOutput = ""
+
+	// given
+	resultExitFunction = func(exitCode int) { resultExitCode = exitCode }
+	resultOutputFunction = func(values ...interface{}) (int, error) {
+		resultOutput = values[0].(string)
+		return len(resultOutput), nil
+	}
+
+	server := httptest.NewServer(NewDaemon())
+	defer server.Close()
+
+	// when
+	FetchCheckResultAndExit(server.URL, "missing", time.Second)
+
+	// then
+	assert.Equal(t, int(StateUnknown().ExitCode()), resultExitCode)
+	assert.Equal(t, "MISSING UNKNOWN - nagopher: daemon returned status [404] (check [missing] is not registered)\n", resultOutput)
+}
+
+func newMockDaemonCheck() Check {
+	warningThreshold := NewBounds(LowerBound(10), UpperBound(80))
+	check := NewCheck("usage", NewSummarizer())
+	check.AttachResources(newMockDaemonResource())
+	check.AttachContexts(NewScalarContext("usage", &warningThreshold, nil))
+
+	return check
+}
+
+type mockDaemonResource struct {
+	Resource
+}
+
+func newMockDaemonResource() Resource {
+	return &mockDaemonResource{
+		Resource: NewResource(),
+	}
+}
+
+func (r mockDaemonResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	return []Metric{
+		MustNewNumericMetric("usage1", 49.4, "%", nil, "usage"),
+		MustNewNumericMetric("usage2", 92.6, "%", nil, "usage"),
+	}, nil
+}
+
+// newMockDaemon returns a daemon whose timers only fire when requested by the test. Each timer requested by a scheduled
+// job is sent to the returned channel, so that receiving the next timer also waits for the previous execution to end.
+func newMockDaemon(options ...DaemonOpt) (*daemon, chan chan time.Time) {
+	timers := make(chan chan time.Time, 16)
+	daemon := NewDaemon(options...).(*daemon)
+	daemon.after = func(time.Duration) <-chan time.Time {
+		timer := make(chan time.Time, 1)
+		timers <- timer
+		return timer
+	}
+
+	return daemon, timers
+}
+
+func fireDaemonTimer(timers chan chan time.Time) {
+	timer := <-timers
+	timer <- time.Now()
+}
@@ -0,0 +1,86 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Labels represents a set of key/value pairs, which are used to further distinguish metrics sharing the same name or
+// context, e.g. a mount point or backend host. They can also be used as a selector for matching other label sets.
+type Labels map[string]string
+
+var labelTemplateRegexp = regexp.MustCompile(`\{(\w+)\}`)
+
+// Keys returns all label keys in ascending order
+func (l Labels) Keys() []string {
+	keys := make([]string, 0, len(l))
+	for key := range l {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Matches returns true if all key/value pairs of the given selector are also part of this label set. An empty selector
+// matches any label set.
+func (l Labels) Matches(selector Labels) bool {
+	for key, expectedValue := range selector {
+		if value, ok := l[key]; !ok || value != expectedValue {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Render replaces all placeholders in the format of {key} within the given template with the value of the according
+// label. Placeholders referring to unknown labels are kept as-is.
+func (l Labels) Render(template string) string {
+	return labelTemplateRegexp.ReplaceAllStringFunc(template, func(placeholder string) string {
+		if value, ok := l[placeholder[1:len(placeholder)-1]]; ok {
+			return value
+		}
+
+		return placeholder
+	})
+}
+
+// Copy returns a shallow copy of the label set, which can be safely modified
+func (l Labels) Copy() Labels {
+	labels := make(Labels, len(l))
+	for key, value := range l {
+		labels[key] = value
+	}
+
+	return labels
+}
+
+func (l Labels) String() string {
+	parts := make([]string, 0, len(l))
+	for _, key := range l.Keys() {
+		parts = append(parts, fmt.Sprintf("%s=%q", key, l[key]))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,78 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLabels_Keys(t *testing.T) {
+	// given
+	labels := Labels{"mount": "/var", "device": "sda1"}
+
+	// then
+	assert.Equal(t, []string{"device", "mount"}, labels.Keys())
+	assert.Equal(t, []string{}, Labels{}.Keys())
+}
+
+func TestLabels_Matches(t *testing.T) {
+	// given
+	labels := Labels{"mount": "/var", "device": "sda1"}
+
+	// then
+	assert.True(t, labels.Matches(nil))
+	assert.True(t, labels.Matches(Labels{"mount": "/var"}))
+	assert.True(t, labels.Matches(Labels{"mount": "/var", "device": "sda1"}))
+	assert.False(t, labels.Matches(Labels{"mount": "/tmp"}))
+	assert.False(t, labels.Matches(Labels{"host": "localhost"}))
+	assert.False(t, Labels{}.Matches(Labels{"mount": "/var"}))
+}
+
+func TestLabels_Render(t *testing.T) {
+	// given
+	labels := Labels{"mount": "/var", "host": "db 1"}
+
+	// then
+	assert.Equal(t, "/var_used", labels.Render("{mount}_used"))
+	assert.Equal(t, "latency of db 1 at {port}", labels.Render("latency of {host} at {port}"))
+	assert.Equal(t, "plain", labels.Render("plain"))
+}
+
+func TestLabels_Copy(t *testing.T) {
+	// given
+	labels := Labels{"mount": "/var"}
+
+	// when
+	copiedLabels := labels.Copy()
+	copiedLabels["mount"] = "/tmp"
+
+	// then
+	assert.Equal(t, "/var", labels["mount"])
+	assert.Equal(t, "/tmp", copiedLabels["mount"])
+}
+
+func TestLabels_String(t *testing.T) {
+	// given
+	labels := Labels{"mount": "/var", "name": "say \"hi\""}
+
+	// then
+	assert.Equal(t, `{mount="/var",name="say \"hi\""}`, labels.String())
+	assert.Equal(t, "{}", Labels{}.String())
+}
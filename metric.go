@@ -35,21 +35,78 @@ type Metric interface {
 	ContextName() string
 }
 
+// LabeledMetric is a Metric with labels like {mount: /var}, which are used for selecting contexts and rendering names
+type LabeledMetric interface {
+	Metric
+	Labels() Labels
+}
+
+// MetricOpt is a type alias for functional options used by all metric constructors
+type MetricOpt func(*baseMetric)
+
 type baseMetric struct {
 	name        string
 	valueUnit   string
 	valueRange  OptionalBounds
 	contextName optional.String
+	labels      Labels
+
+	durationUnit string
 }
 
-func newBaseMetric(name string, valueUnit string, valueRange *Bounds, contextName string) (*baseMetric, error) {
-	if name == "" {
-		return nil, errors.New("metric name must not be empty")
+// MetricLabels is a functional option for metric constructors, which attaches the given labels to the metric. The
+// metric name may contain placeholders like {mount}, which get replaced by the value of the according label.
+func MetricLabels(labels Labels) MetricOpt {
+	return func(m *baseMetric) {
+		for key, value := range labels {
+			m.labels[key] = value
+		}
+	}
+}
+
+// MetricLabel is a functional option for metric constructors, which attaches a single label to the metric
+func MetricLabel(key string, value string) MetricOpt {
+	return func(m *baseMetric) {
+		m.labels[key] = value
+	}
+}
+
+func metricLabels(metric Metric) Labels {
+	if labeledMetric, ok := metric.(LabeledMetric); ok {
+		return labeledMetric.Labels()
+	}
+
+	return Labels{}
+}
+
+func newBaseMetric(name string, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) (*baseMetric, error) {
+	baseMetric, err := buildBaseMetric(name, valueUnit, valueRange, contextName, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseMetric.durationUnit != "" {
+		return nil, errors.New("duration unit can only be set for duration metrics")
 	}
 
+	return baseMetric, nil
+}
+
+// buildBaseMetric creates a new baseMetric without rejecting options specific to a metric type, which must therefore be
+// handled by the caller
+func buildBaseMetric(name string, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) (*baseMetric, error) {
 	baseMetric := &baseMetric{
-		name:      name,
 		valueUnit: valueUnit,
+		labels:    make(Labels),
+	}
+
+	for _, option := range options {
+		option(baseMetric)
+	}
+
+	baseMetric.name = baseMetric.labels.Render(name)
+	if baseMetric.name == "" {
+		return nil, errors.New("metric name must not be empty")
 	}
 
 	if valueRange != nil {
@@ -81,3 +138,7 @@ func (m baseMetric) ValueRange() OptionalBounds {
 func (m baseMetric) ContextName() string {
 	return m.contextName.OrElse(m.name)
 }
+
+func (m baseMetric) Labels() Labels {
+	return m.labels
+}
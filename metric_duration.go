@@ -0,0 +1,118 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"time"
+)
+
+// DurationMetric represents a NumericMetric storing a time.Duration. Its numeric value is expressed in the unit being
+// used for performance data, which is either "s", "ms" or "us".
+type DurationMetric interface {
+	NumericMetric
+
+	Duration() time.Duration
+}
+
+type durationMetric struct {
+	numericMetric
+	duration time.Duration
+}
+
+// AutomaticDurationUnit can be passed to MetricDurationUnit(), so that the largest unit with a value of at least one
+// gets chosen for each value. As the unit may change between executions, this should not be used for graphed metrics.
+const AutomaticDurationUnit = "auto"
+
+var durationUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+}
+
+// NewDurationMetric instantiates a new DurationMetric with the given parameters. Unless the unit has been set using
+// MetricDurationUnit(), the value is expressed in seconds.
+func NewDurationMetric(name string, value time.Duration, contextName string, options ...MetricOpt) (DurationMetric, error) {
+	baseMetric, err := buildBaseMetric(name, "", nil, contextName, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch baseMetric.durationUnit {
+	case "":
+		baseMetric.valueUnit = "s"
+	case AutomaticDurationUnit:
+		baseMetric.valueUnit = automaticDurationUnit(value)
+	default:
+		baseMetric.valueUnit = baseMetric.durationUnit
+	}
+	unit, ok := durationUnits[baseMetric.valueUnit]
+	if !ok {
+		return nil, fmt.Errorf("duration unit [%s] is not supported, expected one of [s, ms, us, %s]",
+			baseMetric.valueUnit, AutomaticDurationUnit)
+	}
+
+	durationMetric := &durationMetric{
+		numericMetric: numericMetric{
+			baseMetric: *baseMetric,
+			value:      float64(value) / float64(unit),
+		},
+		duration: value,
+	}
+
+	return durationMetric, nil
+}
+
+// MustNewDurationMetric calls NewDurationMetric and panics in case the creation of a metric instance fails
+func MustNewDurationMetric(name string, value time.Duration, contextName string, options ...MetricOpt) DurationMetric {
+	metric, err := NewDurationMetric(name, value, contextName, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return metric
+}
+
+// MetricDurationUnit is a functional option for NewDurationMetric(), which sets the unit ("s", "ms", "us" or
+// AutomaticDurationUnit) used for the numeric value and performance data of the metric instead of seconds. Constructors
+// of other metric types return an error when this option is passed.
+func MetricDurationUnit(unit string) MetricOpt {
+	return func(m *baseMetric) {
+		m.durationUnit = unit
+	}
+}
+
+func automaticDurationUnit(value time.Duration) string {
+	if value < 0 {
+		value = -value
+	}
+
+	switch {
+	case value >= time.Second || value == 0:
+		return "s"
+	case value >= time.Millisecond:
+		return "ms"
+	default:
+		return "us"
+	}
+}
+
+func (m durationMetric) Duration() time.Duration {
+	return m.duration
+}
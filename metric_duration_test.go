@@ -0,0 +1,96 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewDurationMetric(t *testing.T) {
+	// when
+	metric1, err1 := NewDurationMetric("duration", 2*time.Second, "")
+	metric2, err2 := NewDurationMetric("", time.Second, "")
+	metric3, err3 := NewDurationMetric("duration", time.Second, "", MetricDurationUnit("h"))
+
+	// then
+	assert.NoError(t, err1)
+	assert.Implements(t, (*DurationMetric)(nil), metric1)
+	assert.Implements(t, (*NumericMetric)(nil), metric1)
+	assert.Error(t, err2)
+	assert.Nil(t, metric2)
+	assert.EqualError(t, err3, "duration unit [h] is not supported, expected one of [s, ms, us, auto]")
+	assert.Nil(t, metric3)
+}
+
+func TestMetricDurationUnit_OtherMetrics(t *testing.T) {
+	// when
+	metric1, err1 := NewNumericMetric("usage", 1, "%", nil, "", MetricDurationUnit("ms"))
+	metric2, err2 := NewStringMetric("status", "online", "", MetricDurationUnit("ms"))
+
+	// then
+	assert.EqualError(t, err1, "duration unit can only be set for duration metrics")
+	assert.Nil(t, metric1)
+	assert.EqualError(t, err2, "duration unit can only be set for duration metrics")
+	assert.Nil(t, metric2)
+}
+
+func TestMustNewDurationMetric(t *testing.T) {
+	assert.NotPanics(t, func() {
+		MustNewDurationMetric("valid", time.Second, "")
+	})
+
+	assert.Panics(t, func() {
+		MustNewDurationMetric("", time.Second, "")
+	})
+}
+
+func TestDurationMetric_Units(t *testing.T) {
+	// given
+	metric1 := MustNewDurationMetric("duration", 1500*time.Millisecond, "")
+	metric2 := MustNewDurationMetric("duration", 250*time.Millisecond, "")
+	metric3 := MustNewDurationMetric("duration", 0, "")
+	metric4 := MustNewDurationMetric("duration", 1500*time.Nanosecond, "", MetricDurationUnit("us"))
+	metric5 := MustNewDurationMetric("duration", 2*time.Second, "", MetricDurationUnit("ms"))
+
+	// then
+	assert.Equal(t, "1.5s", metric1.ToNagiosValue())
+	assert.Equal(t, "0.25s", metric2.ToNagiosValue())
+	assert.Equal(t, "0s", metric3.ToNagiosValue())
+	assert.Equal(t, "1.5us", metric4.ToNagiosValue())
+	assert.Equal(t, "2000ms", metric5.ToNagiosValue())
+	assert.Equal(t, 2000.0, metric5.Value())
+	assert.Equal(t, 2*time.Second, metric5.Duration())
+}
+
+func TestDurationMetric_AutomaticUnit(t *testing.T) {
+	// given
+	unit := MetricDurationUnit(AutomaticDurationUnit)
+	metric1 := MustNewDurationMetric("duration", 1500*time.Millisecond, "", unit)
+	metric2 := MustNewDurationMetric("duration", 250*time.Millisecond, "", unit)
+	metric3 := MustNewDurationMetric("duration", 1500*time.Nanosecond, "", unit)
+	metric4 := MustNewDurationMetric("duration", 0, "", unit)
+
+	// then
+	assert.Equal(t, "1.5s", metric1.ToNagiosValue())
+	assert.Equal(t, "250ms", metric2.ToNagiosValue())
+	assert.Equal(t, "1.5us", metric3.ToNagiosValue())
+	assert.Equal(t, "0s", metric4.ToNagiosValue())
+}
@@ -0,0 +1,218 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// HistogramMetric represents a Metric storing a distribution of values, either as cumulative buckets or raw samples
+type HistogramMetric interface {
+	Metric
+
+	Count() uint64
+	Quantile(q float64) float64
+	Buckets() []HistogramBucket
+}
+
+// HistogramBucket represents a single cumulative histogram bucket, which counts all observations being less than or
+// equal to the given upper bound, as exposed by Prometheus.
+type HistogramBucket interface {
+	UpperBound() float64
+	Count() uint64
+}
+
+type histogramBucket struct {
+	upperBound float64
+	count      uint64
+}
+
+type histogramMetric struct {
+	baseMetric
+	buckets []HistogramBucket
+	samples []float64
+}
+
+// NewHistogramBucket instantiates a new cumulative HistogramBucket with the given upper bound and count
+func NewHistogramBucket(upperBound float64, count uint64) HistogramBucket {
+	return &histogramBucket{
+		upperBound: upperBound,
+		count:      count,
+	}
+}
+
+// NewHistogramMetric instantiates a new HistogramMetric based on cumulative buckets. The buckets must be sorted by
+// their upper bound and must not contain decreasing counts. Quantiles are linearly interpolated within a bucket.
+func NewHistogramMetric(name string, buckets []HistogramBucket, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) (HistogramMetric, error) {
+	baseMetric, err := newBaseMetric(name, valueUnit, valueRange, contextName, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := 1; key < len(buckets); key++ {
+		if buckets[key].UpperBound() <= buckets[key-1].UpperBound() {
+			return nil, errors.New("histogram buckets must be sorted by their upper bound")
+		}
+		if buckets[key].Count() < buckets[key-1].Count() {
+			return nil, errors.New("histogram bucket counts must be cumulative")
+		}
+	}
+
+	histogramMetric := &histogramMetric{
+		baseMetric: *baseMetric,
+		buckets:    append([]HistogramBucket{}, buckets...),
+	}
+
+	return histogramMetric, nil
+}
+
+// NewSampleHistogramMetric instantiates a new HistogramMetric based on raw samples, which allows calculating exact
+// quantiles. Samples being NaN are ignored.
+func NewSampleHistogramMetric(name string, samples []float64, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) (HistogramMetric, error) {
+	baseMetric, err := newBaseMetric(name, valueUnit, valueRange, contextName, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	sortedSamples := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if !math.IsNaN(sample) {
+			sortedSamples = append(sortedSamples, sample)
+		}
+	}
+	sort.Float64s(sortedSamples)
+
+	histogramMetric := &histogramMetric{
+		baseMetric: *baseMetric,
+		samples:    sortedSamples,
+	}
+
+	return histogramMetric, nil
+}
+
+// MustNewHistogramMetric calls NewHistogramMetric and panics in case the creation of a metric instance fails
+func MustNewHistogramMetric(name string, buckets []HistogramBucket, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) HistogramMetric {
+	metric, err := NewHistogramMetric(name, buckets, valueUnit, valueRange, contextName, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return metric
+}
+
+// MustNewSampleHistogramMetric calls NewSampleHistogramMetric and panics in case the creation of a metric instance fails
+func MustNewSampleHistogramMetric(name string, samples []float64, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) HistogramMetric {
+	metric, err := NewSampleHistogramMetric(name, samples, valueUnit, valueRange, contextName, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return metric
+}
+
+func (m histogramMetric) ToNagiosValue() string {
+	return m.ValueString()
+}
+
+func (m histogramMetric) ValueString() string {
+	return fmt.Sprintf("%d", m.Count())
+}
+
+func (m histogramMetric) Count() uint64 {
+	if m.buckets != nil {
+		if len(m.buckets) == 0 {
+			return 0
+		}
+
+		return m.buckets[len(m.buckets)-1].Count()
+	}
+
+	return uint64(len(m.samples))
+}
+
+func (m histogramMetric) Buckets() []HistogramBucket {
+	return m.buckets
+}
+
+func (m histogramMetric) Quantile(q float64) float64 {
+	if math.IsNaN(q) || q < 0 || q > 1 || m.Count() == 0 {
+		return math.NaN()
+	}
+
+	if m.buckets != nil {
+		return m.bucketQuantile(q)
+	}
+
+	return m.sampleQuantile(q)
+}
+
+func (m histogramMetric) sampleQuantile(q float64) float64 {
+	rank := q * float64(len(m.samples)-1)
+	lowerIndex := int(math.Floor(rank))
+	upperIndex := int(math.Ceil(rank))
+	weight := rank - float64(lowerIndex)
+
+	return m.samples[lowerIndex] + weight*(m.samples[upperIndex]-m.samples[lowerIndex])
+}
+
+func (m histogramMetric) bucketQuantile(q float64) float64 {
+	rank := q * float64(m.Count())
+
+	index := sort.Search(len(m.buckets), func(i int) bool {
+		return float64(m.buckets[i].Count()) >= rank
+	})
+	if index >= len(m.buckets) {
+		index = len(m.buckets) - 1
+	}
+
+	bucket := m.buckets[index]
+	if math.IsInf(bucket.UpperBound(), 1) {
+		if index == 0 {
+			return math.NaN()
+		}
+
+		return m.buckets[index-1].UpperBound()
+	}
+
+	lowerBound, lowerCount := float64(0), uint64(0)
+	if index > 0 {
+		lowerBound = m.buckets[index-1].UpperBound()
+		lowerCount = m.buckets[index-1].Count()
+	} else if bucket.UpperBound() < 0 {
+		return bucket.UpperBound()
+	}
+
+	bucketCount := bucket.Count() - lowerCount
+	if bucketCount == 0 {
+		return bucket.UpperBound()
+	}
+
+	return lowerBound + (bucket.UpperBound()-lowerBound)*((rank-float64(lowerCount))/float64(bucketCount))
+}
+
+func (b histogramBucket) UpperBound() float64 {
+	return b.upperBound
+}
+
+func (b histogramBucket) Count() uint64 {
+	return b.count
+}
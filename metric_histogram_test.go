@@ -0,0 +1,109 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestNewHistogramMetric(t *testing.T) {
+	// given
+	validBuckets := []HistogramBucket{NewHistogramBucket(10, 5), NewHistogramBucket(20, 8)}
+	unsortedBuckets := []HistogramBucket{NewHistogramBucket(20, 5), NewHistogramBucket(10, 8)}
+	decreasingBuckets := []HistogramBucket{NewHistogramBucket(10, 8), NewHistogramBucket(20, 5)}
+
+	// when
+	metric1, err1 := NewHistogramMetric("valid", validBuckets, "ms", nil, "")
+	metric2, err2 := NewHistogramMetric("unsorted", unsortedBuckets, "ms", nil, "")
+	metric3, err3 := NewHistogramMetric("decreasing", decreasingBuckets, "ms", nil, "")
+	metric4, err4 := NewHistogramMetric("", validBuckets, "ms", nil, "")
+
+	// then
+	assert.NoError(t, err1)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+	assert.Error(t, err4)
+	assert.Implements(t, (*HistogramMetric)(nil), metric1)
+	assert.Nil(t, metric2)
+	assert.Nil(t, metric3)
+	assert.Nil(t, metric4)
+}
+
+func TestMustNewHistogramMetric(t *testing.T) {
+	assert.NotPanics(t, func() {
+		MustNewHistogramMetric("valid", []HistogramBucket{}, "", nil, "")
+		MustNewSampleHistogramMetric("valid", []float64{}, "", nil, "")
+	})
+
+	assert.Panics(t, func() {
+		MustNewHistogramMetric("", []HistogramBucket{}, "", nil, "")
+	})
+	assert.Panics(t, func() {
+		MustNewSampleHistogramMetric("", []float64{}, "", nil, "")
+	})
+}
+
+func TestHistogramMetric_Quantile_Buckets(t *testing.T) {
+	// given
+	metric := MustNewHistogramMetric("latency", []HistogramBucket{
+		NewHistogramBucket(100, 50),
+		NewHistogramBucket(200, 90),
+		NewHistogramBucket(400, 100),
+		NewHistogramBucket(math.Inf(1), 100),
+	}, "ms", nil, "")
+
+	// then
+	assert.Equal(t, uint64(100), metric.Count())
+	assert.Equal(t, float64(50), metric.Quantile(0.25))
+	assert.Equal(t, float64(100), metric.Quantile(0.5))
+	assert.Equal(t, float64(150), metric.Quantile(0.7))
+	assert.Equal(t, float64(400), metric.Quantile(1))
+	assert.True(t, math.IsNaN(metric.Quantile(1.5)))
+	assert.Equal(t, "100", metric.ToNagiosValue())
+}
+
+func TestHistogramMetric_Quantile_Overflow(t *testing.T) {
+	// given
+	metric := MustNewHistogramMetric("latency", []HistogramBucket{
+		NewHistogramBucket(100, 10),
+		NewHistogramBucket(math.Inf(1), 20),
+	}, "ms", nil, "")
+
+	// then
+	assert.Equal(t, float64(100), metric.Quantile(0.99))
+}
+
+func TestHistogramMetric_Quantile_Samples(t *testing.T) {
+	// given
+	metric1 := MustNewSampleHistogramMetric("latency", []float64{5, 1, 4, 2, 3, math.NaN()}, "ms", nil, "")
+	metric2 := MustNewSampleHistogramMetric("empty", []float64{}, "ms", nil, "")
+
+	// then
+	assert.Equal(t, uint64(5), metric1.Count())
+	assert.Equal(t, float64(1), metric1.Quantile(0))
+	assert.Equal(t, float64(3), metric1.Quantile(0.5))
+	assert.Equal(t, 4.6, metric1.Quantile(0.9))
+	assert.Equal(t, float64(5), metric1.Quantile(1))
+	assert.Nil(t, metric1.Buckets())
+
+	assert.Equal(t, uint64(0), metric2.Count())
+	assert.True(t, math.IsNaN(metric2.Quantile(0.5)))
+}
@@ -20,7 +20,6 @@ package nagopher
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 )
 
@@ -37,8 +36,8 @@ type numericMetric struct {
 }
 
 // NewNumericMetric instantiates a new NumericMetric with the given parameters.
-func NewNumericMetric(name string, value float64, valueUnit string, valueRange *Bounds, contextName string) (NumericMetric, error) {
-	baseMetric, err := newBaseMetric(name, valueUnit, valueRange, contextName)
+func NewNumericMetric(name string, value float64, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) (NumericMetric, error) {
+	baseMetric, err := newBaseMetric(name, valueUnit, valueRange, contextName, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -52,8 +51,8 @@ func NewNumericMetric(name string, value float64, valueUnit string, valueRange *
 }
 
 // MustNewNumericMetric calls MustNewNumericMetric and panics in case the creation of a metric instance fails
-func MustNewNumericMetric(name string, value float64, valueUnit string, valueRange *Bounds, contextName string) NumericMetric {
-	metric, err := NewNumericMetric(name, value, valueUnit, valueRange, contextName)
+func MustNewNumericMetric(name string, value float64, valueUnit string, valueRange *Bounds, contextName string, options ...MetricOpt) NumericMetric {
+	metric, err := NewNumericMetric(name, value, valueUnit, valueRange, contextName, options...)
 	if err != nil {
 		panic(err)
 	}
@@ -62,7 +61,7 @@ func MustNewNumericMetric(name string, value float64, valueUnit string, valueRan
 }
 
 func (m numericMetric) ToNagiosValue() string {
-	if math.IsNaN(m.value) {
+	if isUndefinedValue(m.value) {
 		return "U"
 	}
 
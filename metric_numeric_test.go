@@ -51,16 +51,19 @@ func TestNumericMetric_ToNagiosValue(t *testing.T) {
 	metric1 := MustNewNumericMetric("metric 1", 10, "B", nil, "")
 	metric2 := MustNewNumericMetric("metric 2", 13.37, "B", nil, "")
 	metric3 := MustNewNumericMetric("metric 3", math.NaN(), "", nil, "")
+	metric4 := MustNewNumericMetric("metric 4", math.Inf(-1), "", nil, "")
 
 	// when
 	value1 := metric1.ToNagiosValue()
 	value2 := metric2.ToNagiosValue()
 	value3 := metric3.ToNagiosValue()
+	value4 := metric4.ToNagiosValue()
 
 	// then
 	assert.Equal(t, "10B", value1)
 	assert.Equal(t, "13.37B", value2)
 	assert.Equal(t, "U", value3)
+	assert.Equal(t, "U", value4)
 }
 
 func TestNumericMetric_Value(t *testing.T) {
@@ -0,0 +1,96 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SetMetric represents a Metric storing a set of unique strings, e.g. the names of running services or cluster members
+type SetMetric interface {
+	Metric
+
+	Value() []string
+	Contains(member string) bool
+}
+
+type setMetric struct {
+	baseMetric
+	members []string
+}
+
+// NewSetMetric instantiates a new SetMetric with the given parameters. Duplicate members are removed and the remaining
+// members get sorted alphabetically.
+func NewSetMetric(name string, members []string, contextName string, options ...MetricOpt) (SetMetric, error) {
+	baseMetric, err := newBaseMetric(name, "", nil, contextName, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	setMetric := &setMetric{
+		baseMetric: *baseMetric,
+		members:    uniqueSortedStrings(members),
+	}
+
+	return setMetric, nil
+}
+
+// MustNewSetMetric calls NewSetMetric and panics in case the creation of a metric instance fails
+func MustNewSetMetric(name string, members []string, contextName string, options ...MetricOpt) SetMetric {
+	metric, err := NewSetMetric(name, members, contextName, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return metric
+}
+
+func uniqueSortedStrings(values []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func (m setMetric) ToNagiosValue() string {
+	return strconv.Itoa(len(m.members))
+}
+
+func (m setMetric) Value() []string {
+	return append([]string{}, m.members...)
+}
+
+func (m setMetric) ValueString() string {
+	return strings.Join(m.members, ", ")
+}
+
+func (m setMetric) Contains(member string) bool {
+	index := sort.SearchStrings(m.members, member)
+	return index < len(m.members) && m.members[index] == member
+}
@@ -0,0 +1,62 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewSetMetric(t *testing.T) {
+	// when
+	metric1, err1 := NewSetMetric("set", []string{"b", "a", "b"}, "")
+	metric2, err2 := NewSetMetric("", nil, "")
+
+	// then
+	assert.NoError(t, err1)
+	assert.Error(t, err2)
+	assert.Implements(t, (*SetMetric)(nil), metric1)
+	assert.Equal(t, []string{"a", "b"}, metric1.Value())
+	assert.Nil(t, metric2)
+}
+
+func TestMustNewSetMetric(t *testing.T) {
+	assert.NotPanics(t, func() {
+		MustNewSetMetric("valid", nil, "")
+	})
+
+	assert.Panics(t, func() {
+		MustNewSetMetric("", nil, "")
+	})
+}
+
+func TestSetMetric_Values(t *testing.T) {
+	// given
+	metric1 := MustNewSetMetric("members", []string{"node3", "node1", "node2"}, "")
+	metric2 := MustNewSetMetric("members", nil, "")
+
+	// then
+	assert.Equal(t, "3", metric1.ToNagiosValue())
+	assert.Equal(t, "node1, node2, node3", metric1.ValueString())
+	assert.True(t, metric1.Contains("node2"))
+	assert.False(t, metric1.Contains("node4"))
+	assert.Equal(t, "0", metric2.ToNagiosValue())
+	assert.Equal(t, []string{}, metric2.Value())
+	assert.False(t, metric2.Contains(""))
+}
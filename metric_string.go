@@ -31,8 +31,8 @@ type stringMetric struct {
 }
 
 // NewStringMetric instantiates a new StringMetric with the given parameters.
-func NewStringMetric(name string, value string, contextName string) (StringMetric, error) {
-	baseMetric, err := newBaseMetric(name, "", nil, contextName)
+func NewStringMetric(name string, value string, contextName string, options ...MetricOpt) (StringMetric, error) {
+	baseMetric, err := newBaseMetric(name, "", nil, contextName, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +46,8 @@ func NewStringMetric(name string, value string, contextName string) (StringMetri
 }
 
 // MustNewStringMetric calls NewStringMetric and panics in case the creation of a metric instance fails
-func MustNewStringMetric(name string, value string, contextName string) StringMetric {
-	metric, err := NewStringMetric(name, value, contextName)
+func MustNewStringMetric(name string, value string, contextName string, options ...MetricOpt) StringMetric {
+	metric, err := NewStringMetric(name, value, contextName, options...)
 	if err != nil {
 		panic(err)
 	}
@@ -64,3 +64,20 @@ func TestBaseMetric_ContextName_Empty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "metric", metric.ContextName())
 }
+
+func TestNewBaseMetric_Labels(t *testing.T) {
+	// when
+	metric1, err1 := newBaseMetric("{mount}_used", "B", nil, "disk", MetricLabels(Labels{"mount": "/var"}))
+	metric2, err2 := newBaseMetric("latency", "ms", nil, "", MetricLabel("host", "db1"), MetricLabel("port", "5432"))
+	metric3, err3 := newBaseMetric("plain", "", nil, "")
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+
+	assert.Equal(t, "/var_used", metric1.Name())
+	assert.Equal(t, Labels{"mount": "/var"}, metric1.Labels())
+	assert.Equal(t, Labels{"host": "db1", "port": "5432"}, metric2.Labels())
+	assert.Equal(t, Labels{}, metric3.Labels())
+}
@@ -0,0 +1,257 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"github.com/markphelps/optional"
+	"math"
+	"reflect"
+)
+
+// MetricTransformer converts a NumericMetric into a new NumericMetric, before it gets evaluated by a context.
+// Transformers can be attached to a single context using ContextTransformers() or to all metrics of a check using
+// CheckTransformers(). Metrics of other types are passed through without modification.
+type MetricTransformer interface {
+	Transform(NumericMetric) (NumericMetric, error)
+}
+
+// MetricTransformerFunc is an adapter to allow the use of ordinary functions as MetricTransformer
+type MetricTransformerFunc func(NumericMetric) (NumericMetric, error)
+
+// TransformedMetric represents a NumericMetric, which has been produced by one or more transformers. The metric
+// originally returned by the resource stays available and gets shown in the verbose output.
+type TransformedMetric interface {
+	NumericMetric
+
+	OriginalMetric() NumericMetric
+}
+
+type transformedMetric struct {
+	NumericMetric
+	original NumericMetric
+}
+
+var unitConversionFactors = []map[string]float64{
+	{"B": 1, "KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12, "KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40},
+	{"s": 1, "ms": 1e-3, "us": 1e-6, "ns": 1e-9, "min": 60, "h": 3600},
+	{"": 1, "%": 1e-2},
+}
+
+// Transform calls f(metric)
+func (f MetricTransformerFunc) Transform(metric NumericMetric) (NumericMetric, error) {
+	return f(metric)
+}
+
+// NewScaleTransformer instantiates a new MetricTransformer, which multiplies the value and value range of a metric by
+// the given factor
+func NewScaleTransformer(factor float64) MetricTransformer {
+	return MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		return deriveNumericMetric(metric, metric.Name(), metric.Value()*factor, metric.ValueUnit(),
+			func(value float64) float64 { return value * factor })
+	})
+}
+
+// NewOffsetTransformer instantiates a new MetricTransformer, which adds the given offset to the value and value range
+// of a metric
+func NewOffsetTransformer(offset float64) MetricTransformer {
+	return MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		return deriveNumericMetric(metric, metric.Name(), metric.Value()+offset, metric.ValueUnit(),
+			func(value float64) float64 { return value + offset })
+	})
+}
+
+// NewUnitTransformer instantiates a new MetricTransformer, which converts the value of a metric into the given unit.
+// Supported are byte units (B, KB, MB, GB, TB, KiB, MiB, GiB, TiB), time units (s, ms, us, ns, min, h) and ratios
+// without unit into percentages and vice versa.
+func NewUnitTransformer(unit string) MetricTransformer {
+	return MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		for _, factors := range unitConversionFactors {
+			sourceFactor, sourceOk := factors[metric.ValueUnit()]
+			targetFactor, targetOk := factors[unit]
+			if !sourceOk || !targetOk {
+				continue
+			}
+
+			factor := sourceFactor / targetFactor
+			return deriveNumericMetric(metric, metric.Name(), metric.Value()*factor, unit,
+				func(value float64) float64 { return value * factor })
+		}
+
+		return nil, fmt.Errorf("nagopher: can not convert metric [%s] from unit [%s] to [%s]",
+			metric.Name(), metric.ValueUnit(), unit)
+	})
+}
+
+// NewInvertTransformer instantiates a new MetricTransformer, which mirrors the value of a metric within its value
+// range, e.g. to convert free into used space. The metric must have a value range with lower and upper bound.
+func NewInvertTransformer() MetricTransformer {
+	return MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		valueRange, err := metric.ValueRange().Get()
+		if err != nil || !valueRange.Lower().Present() || !valueRange.Upper().Present() {
+			return nil, fmt.Errorf("nagopher: can not invert metric [%s] without bounded value range", metric.Name())
+		}
+
+		lower, upper := valueRange.Lower().OrElse(0), valueRange.Upper().OrElse(0)
+		return deriveNumericMetric(metric, metric.Name(), lower+upper-metric.Value(), metric.ValueUnit(), nil)
+	})
+}
+
+// NewClampTransformer instantiates a new MetricTransformer, which limits the value of a metric to the given minimum and
+// maximum, e.g. to cap ratios computed from racy counters at 100 percent. The value range of the metric is kept as-is.
+func NewClampTransformer(min float64, max float64) MetricTransformer {
+	return MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		if min > max {
+			return nil, fmt.Errorf("nagopher: can not clamp metric [%s] to empty range [%g, %g]", metric.Name(), min, max)
+		}
+
+		value := metric.Value()
+		if value < min {
+			value = min
+		} else if value > max {
+			value = max
+		}
+
+		return deriveNumericMetric(metric, metric.Name(), value, metric.ValueUnit(), nil)
+	})
+}
+
+// NewMovingAverageTransformer instantiates a new MetricTransformer, which replaces the value of a metric with the
+// average of the last samples, including the current value. Samples are kept separately for each metric, keyed by the
+// metric name followed by its labels, e.g. used{mount="/var"}. It is the callers duty to persist the given samples
+// between executions, similar to the previous value of a DeltaContext. Only the given amount of samples are retained.
+func NewMovingAverageTransformer(samples map[string][]float64, size int) MetricTransformer {
+	return MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		if samples == nil || size < 1 {
+			return nil, fmt.Errorf("nagopher: invalid moving average configuration for metric [%s]", metric.Name())
+		}
+
+		key := movingAverageKey(metric)
+		metricSamples := samples[key]
+		if !math.IsNaN(metric.Value()) {
+			metricSamples = append(metricSamples, metric.Value())
+		}
+		if len(metricSamples) > size {
+			metricSamples = metricSamples[len(metricSamples)-size:]
+		}
+		if len(metricSamples) == 0 {
+			return deriveNumericMetric(metric, metric.Name(), math.NaN(), metric.ValueUnit(), nil)
+		}
+		samples[key] = metricSamples
+
+		sum := float64(0)
+		for _, sample := range metricSamples {
+			sum += sample
+		}
+
+		return deriveNumericMetric(metric, metric.Name(), sum/float64(len(metricSamples)), metric.ValueUnit(), nil)
+	})
+}
+
+func movingAverageKey(metric Metric) string {
+	labels := metricLabels(metric)
+	if len(labels) == 0 {
+		return metric.Name()
+	}
+
+	return metric.Name() + labels.String()
+}
+
+// NewRenameTransformer instantiates a new MetricTransformer, which renames a metric. The name may contain placeholders
+// like {mount}, which get replaced by the value of the according label.
+func NewRenameTransformer(name string) MetricTransformer {
+	return MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		return deriveNumericMetric(metric, name, metric.Value(), metric.ValueUnit(), nil)
+	})
+}
+
+func (m transformedMetric) OriginalMetric() NumericMetric {
+	return m.original
+}
+
+func (m transformedMetric) Labels() Labels {
+	return metricLabels(m.NumericMetric)
+}
+
+// deriveNumericMetric creates a new TransformedMetric based on the given metric. The value range of the metric gets
+// converted using the given function or kept as-is, when no function was given.
+func deriveNumericMetric(metric NumericMetric, name string, value float64, valueUnit string,
+	convertRange func(float64) float64) (NumericMetric, error) {
+	var valueRange *Bounds
+	if bounds, err := metric.ValueRange().Get(); err == nil {
+		if convertRange != nil {
+			bounds = convertBounds(bounds, convertRange)
+		}
+		valueRange = &bounds
+	}
+
+	contextName := metric.ContextName()
+	derivedMetric, err := NewNumericMetric(name, value, valueUnit, valueRange, contextName, MetricLabels(metricLabels(metric)))
+	if err != nil {
+		return nil, err
+	}
+
+	original := metric
+	if transformed, ok := metric.(TransformedMetric); ok {
+		original = transformed.OriginalMetric()
+	}
+
+	return &transformedMetric{NumericMetric: derivedMetric, original: original}, nil
+}
+
+func convertBounds(bounds Bounds, convert func(float64) float64) Bounds {
+	// adding zero normalizes negative zero, which would otherwise be rendered as "-0"
+	lower, upper := bounds.Lower(), bounds.Upper()
+	lower.If(func(value float64) { lower = optional.NewFloat64(convert(value) + 0) })
+	upper.If(func(value float64) { upper = optional.NewFloat64(convert(value) + 0) })
+	if convert(1) < convert(0) {
+		lower, upper = upper, lower
+	}
+
+	options := []BoundsOpt{InvertedBounds(bounds.IsInverted())}
+	lower.If(func(value float64) { options = append(options, LowerBound(value)) })
+	upper.If(func(value float64) { options = append(options, UpperBound(value)) })
+
+	return NewBounds(options...)
+}
+
+func transformMetric(metric Metric, transformers []MetricTransformer) (Metric, error) {
+	if len(transformers) == 0 {
+		return metric, nil
+	}
+
+	numericMetric, ok := metric.(NumericMetric)
+	if !ok {
+		return metric, nil
+	}
+
+	for _, transformer := range transformers {
+		transformed, err := transformer.Transform(numericMetric)
+		if err != nil {
+			return nil, err
+		}
+		if transformed == nil {
+			return nil, fmt.Errorf("nagopher: transformer [%s] did not return a metric for [%s]",
+				reflect.TypeOf(transformer), numericMetric.Name())
+		}
+		numericMetric = transformed
+	}
+
+	return numericMetric, nil
+}
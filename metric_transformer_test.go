@@ -0,0 +1,202 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestMetricTransformers(t *testing.T) {
+	// given
+	valueRange, _ := NewBoundsFromNagiosRange("0:200")
+	metric := MustNewNumericMetric("free", 50, "B", &valueRange, "space")
+
+	// when
+	scaled, err1 := NewScaleTransformer(0.5).Transform(metric)
+	offset, err2 := NewOffsetTransformer(-10).Transform(metric)
+	inverted, err3 := NewInvertTransformer().Transform(metric)
+	negated, err4 := NewScaleTransformer(-1).Transform(metric)
+	clamped1, err5 := NewClampTransformer(0, 40).Transform(metric)
+	clamped2, err6 := NewClampTransformer(60, 100).Transform(metric)
+	unclamped, err7 := NewClampTransformer(0, 100).Transform(metric)
+
+	// then
+	assert.NoError(t, err1)
+	assert.Equal(t, 25.0, scaled.Value())
+	assert.Equal(t, ":100", scaled.ValueRange().OrElse(NewBounds()).ToNagiosRange())
+	assert.Equal(t, "space", scaled.ContextName())
+
+	assert.NoError(t, err2)
+	assert.Equal(t, 40.0, offset.Value())
+	assert.Equal(t, "-10:190", offset.ValueRange().OrElse(NewBounds()).ToNagiosRange())
+
+	assert.NoError(t, err3)
+	assert.Equal(t, 150.0, inverted.Value())
+	assert.Equal(t, "B", inverted.ValueUnit())
+
+	assert.NoError(t, err4)
+	assert.Equal(t, -50.0, negated.Value())
+	assert.Equal(t, "-200:0", negated.ValueRange().OrElse(NewBounds()).ToNagiosRange())
+
+	assert.NoError(t, err5)
+	assert.Equal(t, 40.0, clamped1.Value())
+	assert.Equal(t, ":200", clamped1.ValueRange().OrElse(NewBounds()).ToNagiosRange())
+	assert.NoError(t, err6)
+	assert.Equal(t, 60.0, clamped2.Value())
+	assert.NoError(t, err7)
+	assert.Equal(t, 50.0, unclamped.Value())
+}
+
+func TestNewClampTransformer_Invalid(t *testing.T) {
+	// given
+	metric1 := MustNewNumericMetric("metric1", 10, "", nil, "")
+	metric2 := MustNewNumericMetric("metric2", math.NaN(), "", nil, "")
+
+	// when
+	_, err1 := NewClampTransformer(100, 0).Transform(metric1)
+	clamped, err2 := NewClampTransformer(0, 100).Transform(metric2)
+
+	// then
+	assert.EqualError(t, err1, "nagopher: can not clamp metric [metric1] to empty range [100, 0]")
+	assert.NoError(t, err2)
+	assert.True(t, math.IsNaN(clamped.Value()))
+}
+
+func TestNewUnitTransformer(t *testing.T) {
+	// given
+	bytesMetric := MustNewNumericMetric("size", 3*(1<<30), "B", nil, "")
+	ratioMetric := MustNewNumericMetric("ratio", 0.25, "", nil, "")
+	timeMetric := MustNewNumericMetric("time", 1500, "ms", nil, "")
+
+	// when
+	gibibytes, err1 := NewUnitTransformer("GiB").Transform(bytesMetric)
+	percent, err2 := NewUnitTransformer("%").Transform(ratioMetric)
+	seconds, err3 := NewUnitTransformer("s").Transform(timeMetric)
+	_, err4 := NewUnitTransformer("s").Transform(bytesMetric)
+
+	// then
+	assert.NoError(t, err1)
+	assert.Equal(t, "3GiB", gibibytes.ToNagiosValue())
+	assert.NoError(t, err2)
+	assert.Equal(t, "25%", percent.ToNagiosValue())
+	assert.NoError(t, err3)
+	assert.Equal(t, "1.5s", seconds.ToNagiosValue())
+	assert.EqualError(t, err4, "nagopher: can not convert metric [size] from unit [B] to [s]")
+}
+
+func TestNewInvertTransformer_Unbounded(t *testing.T) {
+	// given
+	valueRange := NewBounds(LowerBound(0))
+	metric1 := MustNewNumericMetric("metric1", 10, "", nil, "")
+	metric2 := MustNewNumericMetric("metric2", 10, "", &valueRange, "")
+
+	// when
+	_, err1 := NewInvertTransformer().Transform(metric1)
+	_, err2 := NewInvertTransformer().Transform(metric2)
+
+	// then
+	assert.EqualError(t, err1, "nagopher: can not invert metric [metric1] without bounded value range")
+	assert.EqualError(t, err2, "nagopher: can not invert metric [metric2] without bounded value range")
+}
+
+func TestNewMovingAverageTransformer(t *testing.T) {
+	// given
+	samples := map[string][]float64{"load": {1, 2, 3}}
+	transformer := NewMovingAverageTransformer(samples, 3)
+
+	// when
+	average1, err1 := transformer.Transform(MustNewNumericMetric("load", 7, "", nil, ""))
+	average2, err2 := transformer.Transform(MustNewNumericMetric("load", math.NaN(), "", nil, ""))
+	_, err3 := NewMovingAverageTransformer(nil, 3).Transform(MustNewNumericMetric("load", 1, "", nil, ""))
+
+	// then
+	assert.NoError(t, err1)
+	assert.Equal(t, 4.0, average1.Value())
+	assert.NoError(t, err2)
+	assert.Equal(t, 4.0, average2.Value())
+	assert.Equal(t, map[string][]float64{"load": {2, 3, 7}}, samples)
+	assert.EqualError(t, err3, "nagopher: invalid moving average configuration for metric [load]")
+}
+
+func TestNewMovingAverageTransformer_MultipleMetrics(t *testing.T) {
+	// given
+	samples := make(map[string][]float64)
+	transformer := NewMovingAverageTransformer(samples, 3)
+
+	// when
+	average1, _ := transformer.Transform(MustNewNumericMetric("a", 10, "", nil, ""))
+	average2, _ := transformer.Transform(MustNewNumericMetric("b", 90, "", nil, ""))
+	average3, _ := transformer.Transform(MustNewNumericMetric("used", 20, "", nil, "", MetricLabel("mount", "/var")))
+	average4, _ := transformer.Transform(MustNewNumericMetric("used", 80, "", nil, "", MetricLabel("mount", "/tmp")))
+
+	// then
+	assert.Equal(t, 10.0, average1.Value())
+	assert.Equal(t, 90.0, average2.Value())
+	assert.Equal(t, 20.0, average3.Value())
+	assert.Equal(t, 80.0, average4.Value())
+	assert.Equal(t, map[string][]float64{
+		"a":                  {10},
+		"b":                  {90},
+		`used{mount="/tmp"}`: {80},
+		`used{mount="/var"}`: {20},
+	}, samples)
+}
+
+func TestNewRenameTransformer(t *testing.T) {
+	// given
+	metric := MustNewNumericMetric("free", 10, "", nil, "space", MetricLabel("mount", "/var"))
+
+	// when
+	renamed, err := NewRenameTransformer("used {mount}").Transform(metric)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "used /var", renamed.Name())
+	assert.Equal(t, "space", renamed.ContextName())
+	assert.Equal(t, metricLabels(metric), metricLabels(renamed))
+}
+
+func TestTransformMetric(t *testing.T) {
+	// given
+	metric := MustNewNumericMetric("ratio", 0.4, "", nil, "usage")
+	stringMetric := MustNewStringMetric("version", "1.0", "")
+	failingTransformer := MetricTransformerFunc(func(metric NumericMetric) (NumericMetric, error) {
+		return nil, errors.New("transformer failed")
+	})
+
+	// when
+	transformed, err1 := transformMetric(metric, []MetricTransformer{
+		NewUnitTransformer("%"), NewRenameTransformer("usage"), NewOffsetTransformer(1),
+	})
+	untransformed, err2 := transformMetric(stringMetric, []MetricTransformer{failingTransformer})
+	_, err3 := transformMetric(metric, []MetricTransformer{NewScaleTransformer(2), failingTransformer})
+
+	// then
+	assert.NoError(t, err1)
+	assert.Equal(t, "usage", transformed.Name())
+	assert.Equal(t, "41%", transformed.ToNagiosValue())
+	assert.Equal(t, metric, transformed.(TransformedMetric).OriginalMetric())
+
+	assert.NoError(t, err2)
+	assert.Equal(t, stringMetric, untransformed)
+	assert.EqualError(t, err3, "transformer failed")
+}
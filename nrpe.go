@@ -0,0 +1,443 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NRPECommandFactory creates a new Check instance for an incoming NRPE command. The arguments contain all values which
+// were passed by check_nrpe using the "-a" flag, separated by "!" on the wire.
+type NRPECommandFactory func(arguments []string) (Check, error)
+
+// NRPEServer implements the NRPE v2/v3 protocol and executes registered checks natively, without spawning any plugin
+// processes. Go does not support anonymous Diffie-Hellman cipher suites, so check_nrpe must either be invoked without
+// SSL ("-n") or use certificates when a TLS configuration has been set using NRPETLSConfig(). TLS configurations
+// without any certificate are rejected by NewNRPEServer().
+type NRPEServer interface {
+	Register(command string, factory NRPECommandFactory)
+	Serve(listener net.Listener) error
+	ListenAndServe(address string) error
+	Close() error
+}
+
+// NRPEServerOpt is a type alias for functional options used by NewNRPEServer()
+type NRPEServerOpt func(*nrpeServer)
+
+type nrpeServer struct {
+	runtime           Runtime
+	tlsConfig         *tls.Config
+	allowedHostSpecs  []string
+	allowedNetworks   []*net.IPNet
+	allowArguments    bool
+	connectionTimeout time.Duration
+	commandTimeout    time.Duration
+	maxChecks         int
+	checkSlots        chan struct{}
+
+	mutex     sync.RWMutex
+	commands  map[string]NRPECommandFactory
+	listeners map[net.Listener]struct{}
+	closed    bool
+}
+
+type nrpePacket struct {
+	version    int16
+	packetType int16
+	resultCode int16
+	buffer     string
+}
+
+const (
+	nrpePacketVersion2   = 2
+	nrpePacketVersion3   = 3
+	nrpePacketTypeQuery  = 1
+	nrpePacketTypeResult = 2
+	nrpeV2BufferSize     = 1024
+	nrpeV2PacketSize     = 1036
+	nrpeV3HeaderSize     = 16
+	nrpeV3PaddingSize    = 3
+	nrpeV3MaxBufferSize  = 65536
+	nrpeVersionCommand   = "_NRPE_CHECK"
+)
+
+// NewNRPEServer instantiates a new NRPEServer using the given runtime for executing checks
+func NewNRPEServer(runtime Runtime, options ...NRPEServerOpt) (NRPEServer, error) {
+	server := &nrpeServer{
+		runtime:           runtime,
+		connectionTimeout: 10 * time.Second,
+		commandTimeout:    60 * time.Second,
+		maxChecks:         16,
+		commands:          make(map[string]NRPECommandFactory),
+		listeners:         make(map[net.Listener]struct{}),
+	}
+
+	for _, option := range options {
+		option(server)
+	}
+
+	if tlsConfig := server.tlsConfig; tlsConfig != nil && len(tlsConfig.Certificates) == 0 &&
+		tlsConfig.GetCertificate == nil && tlsConfig.GetConfigForClient == nil {
+		return nil, fmt.Errorf("nrpe tls configuration requires a certificate, as anonymous diffie-hellman is not supported")
+	}
+
+	for _, host := range server.allowedHostSpecs {
+		if !strings.Contains(host, "/") {
+			if strings.Contains(host, ":") {
+				host += "/128"
+			} else {
+				host += "/32"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(host)
+		if err != nil {
+			return nil, fmt.Errorf("nrpe allowed host [%s] is neither a valid address nor network", host)
+		}
+		server.allowedNetworks = append(server.allowedNetworks, network)
+	}
+
+	if server.maxChecks > 0 {
+		server.checkSlots = make(chan struct{}, server.maxChecks)
+	}
+
+	return server, nil
+}
+
+// NRPETLSConfig is a functional option for NewNRPEServer(), which enables TLS using the given configuration. The
+// configuration must provide a certificate, as anonymous Diffie-Hellman is not supported.
+func NRPETLSConfig(tlsConfig *tls.Config) NRPEServerOpt {
+	return func(s *nrpeServer) {
+		s.tlsConfig = tlsConfig
+	}
+}
+
+// NRPEAllowedHosts is a functional option for NewNRPEServer(), which restricts connections to the given addresses or
+// networks in CIDR notation. All hosts are allowed if this option is not specified.
+func NRPEAllowedHosts(hosts ...string) NRPEServerOpt {
+	return func(s *nrpeServer) {
+		s.allowedHostSpecs = append(s.allowedHostSpecs, hosts...)
+	}
+}
+
+// NRPEAllowArguments is a functional option for NewNRPEServer(), which allows passing command arguments to the check
+// factories. Similar to the "dont_blame_nrpe" option of NRPE, arguments are rejected by default.
+func NRPEAllowArguments(state bool) NRPEServerOpt {
+	return func(s *nrpeServer) {
+		s.allowArguments = state
+	}
+}
+
+// NRPEConnectionTimeout is a functional option for NewNRPEServer(), which limits the time for receiving a query and
+// sending back the response. Defaults to 10 seconds.
+func NRPEConnectionTimeout(timeout time.Duration) NRPEServerOpt {
+	return func(s *nrpeServer) {
+		s.connectionTimeout = timeout
+	}
+}
+
+// NRPECommandTimeout is a functional option for NewNRPEServer(), which limits the execution time of a single check.
+// Checks exceeding this limit are reported as UNKNOWN. Defaults to 60 seconds.
+func NRPECommandTimeout(timeout time.Duration) NRPEServerOpt {
+	return func(s *nrpeServer) {
+		s.commandTimeout = timeout
+	}
+}
+
+// NRPEMaxConcurrentChecks is a functional option for NewNRPEServer(), which limits the number of checks being executed at
+// the same time. As checks can not be cancelled, timed out checks keep occupying their slot until they have finished,
+// which prevents hanging checks from piling up. Defaults to 16, while values less than 1 disable the limit.
+func NRPEMaxConcurrentChecks(limit int) NRPEServerOpt {
+	return func(s *nrpeServer) {
+		s.maxChecks = limit
+	}
+}
+
+func (s *nrpeServer) Register(command string, factory NRPECommandFactory) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.commands[command] = factory
+}
+
+func (s *nrpeServer) ListenAndServe(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+func (s *nrpeServer) Serve(listener net.Listener) error {
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		_ = listener.Close()
+		return errors.New("nagopher: nrpe server has been closed")
+	}
+	s.listeners[listener] = struct{}{}
+	s.mutex.Unlock()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			s.mutex.RLock()
+			closed := s.closed
+			s.mutex.RUnlock()
+
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		go s.handleConnection(connection)
+	}
+}
+
+func (s *nrpeServer) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	for listener := range s.listeners {
+		_ = listener.Close()
+	}
+	s.listeners = make(map[net.Listener]struct{})
+
+	return nil
+}
+
+func (s *nrpeServer) handleConnection(connection net.Conn) {
+	defer connection.Close()
+
+	if !s.isAllowedHost(connection.RemoteAddr()) {
+		return
+	}
+
+	if s.connectionTimeout > 0 {
+		_ = connection.SetReadDeadline(time.Now().Add(s.connectionTimeout))
+	}
+
+	query, err := readNRPEPacket(connection)
+	if err != nil || query.packetType != nrpePacketTypeQuery {
+		return
+	}
+
+	resultCode, output := s.executeCommand(query.buffer)
+	response := encodeNRPEPacket(nrpePacket{
+		version:    query.version,
+		packetType: nrpePacketTypeResult,
+		resultCode: resultCode,
+		buffer:     output,
+	})
+
+	if s.connectionTimeout > 0 {
+		_ = connection.SetWriteDeadline(time.Now().Add(s.connectionTimeout))
+	}
+	_, _ = connection.Write(response)
+}
+
+func (s *nrpeServer) isAllowedHost(address net.Addr) bool {
+	if len(s.allowedNetworks) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(address.String())
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	for _, network := range s.allowedNetworks {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *nrpeServer) executeCommand(query string) (int16, string) {
+	parts := strings.Split(query, "!")
+	command, arguments := parts[0], parts[1:]
+
+	if command == nrpeVersionCommand {
+		return int16(StateOk().ExitCode()), "NRPE v3 (nagopher)"
+	}
+
+	s.mutex.RLock()
+	factory, ok := s.commands[command]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return int16(StateUnknown().ExitCode()), fmt.Sprintf("NRPE: Command '%s' not defined", command)
+	}
+	if len(arguments) > 0 && !s.allowArguments {
+		return int16(StateUnknown().ExitCode()), "NRPE: Command arguments are not allowed"
+	}
+
+	var timeoutChannel <-chan time.Time
+	if s.commandTimeout > 0 {
+		timeoutChannel = time.After(s.commandTimeout)
+	}
+
+	if s.checkSlots != nil {
+		select {
+		case s.checkSlots <- struct{}{}:
+		case <-timeoutChannel:
+			return int16(StateUnknown().ExitCode()),
+				fmt.Sprintf("NRPE: Command timed out after %s waiting for a free execution slot", s.commandTimeout)
+		}
+	}
+
+	resultChannel := make(chan CheckResult, 1)
+	go func() {
+		if s.checkSlots != nil {
+			defer func() { <-s.checkSlots }()
+		}
+		resultChannel <- s.executeCheck(command, factory, arguments)
+	}()
+
+	select {
+	case result := <-resultChannel:
+		return int16(result.ExitCode()), result.Output()
+	case <-timeoutChannel:
+		return int16(StateUnknown().ExitCode()), fmt.Sprintf("NRPE: Command timed out after %s", s.commandTimeout)
+	}
+}
+
+func (s *nrpeServer) executeCheck(command string, factory NRPECommandFactory, arguments []string) (result CheckResult) {
+	defer func() {
+		if value := recover(); value != nil {
+			result = newFactoryPanicResult(command, value)
+		}
+	}()
+
+	check, err := factory(arguments)
+	if err != nil {
+		return NewCheckResult(StateUnknown().ExitCode(), fmt.Sprintf("NRPE: %s", err.Error()))
+	}
+
+	return s.runtime.Execute(check)
+}
+
+func encodeNRPEPacket(packet nrpePacket) []byte {
+	var data []byte
+
+	if packet.version == nrpePacketVersion2 {
+		data = make([]byte, nrpeV2PacketSize)
+		binary.BigEndian.PutUint16(data[0:], uint16(packet.version))
+		binary.BigEndian.PutUint16(data[2:], uint16(packet.packetType))
+		binary.BigEndian.PutUint16(data[8:], uint16(packet.resultCode))
+
+		buffer := packet.buffer
+		if len(buffer) > nrpeV2BufferSize-1 {
+			buffer = buffer[:nrpeV2BufferSize-1]
+		}
+		copy(data[10:], buffer)
+	} else {
+		buffer := packet.buffer
+		if len(buffer) > nrpeV3MaxBufferSize-1 {
+			buffer = buffer[:nrpeV3MaxBufferSize-1]
+		}
+
+		bufferLength := len(buffer) + 1
+		data = make([]byte, nrpeV3HeaderSize+bufferLength+nrpeV3PaddingSize)
+		binary.BigEndian.PutUint16(data[0:], uint16(packet.version))
+		binary.BigEndian.PutUint16(data[2:], uint16(packet.packetType))
+		binary.BigEndian.PutUint16(data[8:], uint16(packet.resultCode))
+		binary.BigEndian.PutUint32(data[12:], uint32(bufferLength))
+		copy(data[nrpeV3HeaderSize:], buffer)
+	}
+
+	binary.BigEndian.PutUint32(data[4:], crc32.ChecksumIEEE(data))
+	return data
+}
+
+func readNRPEPacket(reader io.Reader) (nrpePacket, error) {
+	header := make([]byte, nrpeV3HeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nrpePacket{}, err
+	}
+
+	var data []byte
+	var buffer []byte
+	version := int16(binary.BigEndian.Uint16(header[0:]))
+
+	switch version {
+	case nrpePacketVersion2:
+		data = make([]byte, nrpeV2PacketSize)
+		copy(data, header)
+		if _, err := io.ReadFull(reader, data[nrpeV3HeaderSize:]); err != nil {
+			return nrpePacket{}, err
+		}
+		buffer = data[10 : 10+nrpeV2BufferSize]
+
+	case nrpePacketVersion3:
+		bufferLength := binary.BigEndian.Uint32(header[12:])
+		if bufferLength > nrpeV3MaxBufferSize {
+			return nrpePacket{}, fmt.Errorf("nrpe packet buffer length [%d] exceeds maximum", bufferLength)
+		}
+
+		data = make([]byte, nrpeV3HeaderSize+int(bufferLength)+nrpeV3PaddingSize)
+		copy(data, header)
+		if _, err := io.ReadFull(reader, data[nrpeV3HeaderSize:]); err != nil {
+			return nrpePacket{}, err
+		}
+		buffer = data[nrpeV3HeaderSize : nrpeV3HeaderSize+int(bufferLength)]
+
+	default:
+		return nrpePacket{}, fmt.Errorf("unsupported nrpe packet version [%d]", version)
+	}
+
+	expectedChecksum := binary.BigEndian.Uint32(data[4:])
+	binary.BigEndian.PutUint32(data[4:], 0)
+	if actualChecksum := crc32.ChecksumIEEE(data); actualChecksum != expectedChecksum {
+		return nrpePacket{}, fmt.Errorf("nrpe packet checksum mismatch (expected %08x, got %08x)", expectedChecksum, actualChecksum)
+	}
+
+	if index := bytes.IndexByte(buffer, 0); index >= 0 {
+		buffer = buffer[:index]
+	}
+
+	packet := nrpePacket{
+		version:    version,
+		packetType: int16(binary.BigEndian.Uint16(data[2:])),
+		resultCode: int16(binary.BigEndian.Uint16(data[8:])),
+		buffer:     string(buffer),
+	}
+
+	return packet, nil
+}
@@ -0,0 +1,260 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNRPEServer_Execute(t *testing.T) {
+	// given
+	server, address := startMockNRPEServer(t)
+	defer server.Close()
+
+	// when
+	code1, output1, err1 := queryNRPE(address, nrpePacketVersion2, "check_usage", nil)
+	code2, output2, err2 := queryNRPE(address, nrpePacketVersion3, "check_usage", nil)
+	code3, output3, err3 := queryNRPE(address, nrpePacketVersion3, nrpeVersionCommand, nil)
+
+	// then
+	expectedResult := NewRuntime(false).Execute(newMockDaemonCheck())
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, int16(expectedResult.ExitCode()), code1)
+	assert.Equal(t, expectedResult.Output(), output1)
+	assert.Equal(t, int16(expectedResult.ExitCode()), code2)
+	assert.Equal(t, expectedResult.Output(), output2)
+	assert.Equal(t, int16(0), code3)
+	assert.Equal(t, "NRPE v3 (nagopher)", output3)
+}
+
+func TestNRPEServer_Execute_Arguments(t *testing.T) {
+	// given
+	server, address := startMockNRPEServer(t, NRPEAllowArguments(true))
+	defer server.Close()
+
+	// when
+	code1, output1, err1 := queryNRPE(address, nrpePacketVersion3, "check_threshold!10:20", nil)
+	code2, output2, err2 := queryNRPE(address, nrpePacketVersion3, "check_threshold!invalid", nil)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, int16(StateCritical().ExitCode()), code1)
+	assert.Contains(t, output1, "outside range 10:20")
+	assert.Equal(t, int16(StateUnknown().ExitCode()), code2)
+	assert.Contains(t, output2, "NRPE: could not parse range part")
+}
+
+func TestNRPEServer_Execute_Errors(t *testing.T) {
+	// given
+	server, address := startMockNRPEServer(t, NRPECommandTimeout(20*time.Millisecond))
+	defer server.Close()
+
+	// when
+	code1, output1, err1 := queryNRPE(address, nrpePacketVersion2, "check_missing", nil)
+	code2, output2, err2 := queryNRPE(address, nrpePacketVersion2, "check_threshold!10", nil)
+	code3, output3, err3 := queryNRPE(address, nrpePacketVersion2, "check_slow", nil)
+	code4, output4, err4 := queryNRPE(address, nrpePacketVersion2, "check_panic", nil)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.NoError(t, err4)
+	assert.Equal(t, int16(StateUnknown().ExitCode()), code1)
+	assert.Equal(t, "NRPE: Command 'check_missing' not defined", output1)
+	assert.Equal(t, int16(StateUnknown().ExitCode()), code2)
+	assert.Equal(t, "NRPE: Command arguments are not allowed", output2)
+	assert.Equal(t, int16(StateUnknown().ExitCode()), code3)
+	assert.Equal(t, "NRPE: Command timed out after 20ms", output3)
+	assert.Equal(t, int16(StateUnknown().ExitCode()), code4)
+	assert.Equal(t, "CHECK_PANIC UNKNOWN - nagopher: check factory panicked with [invalid state]\n", output4)
+}
+
+func TestNRPEServer_MaxConcurrentChecks(t *testing.T) {
+	// given
+	server, address := startMockNRPEServer(t, NRPECommandTimeout(20*time.Millisecond), NRPEMaxConcurrentChecks(1))
+	defer server.Close()
+
+	// when
+	code1, output1, err1 := queryNRPE(address, nrpePacketVersion2, "check_slow", nil)
+	code2, output2, err2 := queryNRPE(address, nrpePacketVersion2, "check_usage", nil)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, int16(StateUnknown().ExitCode()), code1)
+	assert.Equal(t, "NRPE: Command timed out after 20ms", output1)
+	assert.Equal(t, int16(StateUnknown().ExitCode()), code2)
+	assert.Equal(t, "NRPE: Command timed out after 20ms waiting for a free execution slot", output2)
+}
+
+func TestNRPEServer_AllowedHosts(t *testing.T) {
+	// given
+	server1, address1 := startMockNRPEServer(t, NRPEAllowedHosts("127.0.0.0/8"))
+	defer server1.Close()
+	server2, address2 := startMockNRPEServer(t, NRPEAllowedHosts("192.0.2.1", "2001:db8::1"))
+	defer server2.Close()
+
+	// when
+	_, _, err1 := queryNRPE(address1, nrpePacketVersion3, nrpeVersionCommand, nil)
+	_, _, err2 := queryNRPE(address2, nrpePacketVersion3, nrpeVersionCommand, nil)
+	_, err3 := NewNRPEServer(NewRuntime(false), NRPEAllowedHosts("invalid"))
+
+	// then
+	assert.NoError(t, err1)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+}
+
+func TestNRPEServer_TLS(t *testing.T) {
+	// given
+	tlsServer := httptest.NewTLSServer(nil)
+	defer tlsServer.Close()
+	certificate := tlsServer.TLS.Certificates[0]
+	server, address := startMockNRPEServer(t, NRPETLSConfig(&tls.Config{Certificates: []tls.Certificate{certificate}}))
+	defer server.Close()
+
+	// when
+	code, output, err := queryNRPE(address, nrpePacketVersion3, nrpeVersionCommand, &tls.Config{InsecureSkipVerify: true})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int16(0), code)
+	assert.Equal(t, "NRPE v3 (nagopher)", output)
+}
+
+func TestNRPEServer_TLS_Anonymous(t *testing.T) {
+	// when
+	_, err := NewNRPEServer(NewRuntime(false), NRPETLSConfig(&tls.Config{}))
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestNRPEServer_Close(t *testing.T) {
+	// given
+	server, err := NewNRPEServer(NewRuntime(false))
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+
+	// when
+	closeErr := server.Close()
+	serveErr := server.Serve(listener)
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, closeErr)
+	assert.Error(t, serveErr)
+}
+
+func TestReadNRPEPacket(t *testing.T) {
+	// given
+	validPacket := encodeNRPEPacket(nrpePacket{version: 3, packetType: 1, buffer: "check_usage"})
+	corruptPacket := append([]byte{}, validPacket...)
+	corruptPacket[20] ^= 0xFF
+	invalidPacket := encodeNRPEPacket(nrpePacket{version: 4, packetType: 1, buffer: "check_usage"})
+
+	// when
+	packet, err1 := readNRPEPacket(strings.NewReader(string(validPacket)))
+	_, err2 := readNRPEPacket(strings.NewReader(string(corruptPacket)))
+	_, err3 := readNRPEPacket(strings.NewReader(string(invalidPacket)))
+	_, err4 := readNRPEPacket(strings.NewReader(string(validPacket[:10])))
+
+	// then
+	assert.NoError(t, err1)
+	assert.Equal(t, "check_usage", packet.buffer)
+	assert.Equal(t, int16(1), packet.packetType)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+	assert.Error(t, err4)
+	assert.Equal(t, nrpeV2PacketSize, len(encodeNRPEPacket(nrpePacket{version: 2, buffer: strings.Repeat("x", 2048)})))
+}
+
+func startMockNRPEServer(t *testing.T, options ...NRPEServerOpt) (NRPEServer, string) {
+	server, err := NewNRPEServer(NewRuntime(false), options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Register("check_usage", func(arguments []string) (Check, error) {
+		return newMockDaemonCheck(), nil
+	})
+	server.Register("check_threshold", func(arguments []string) (Check, error) {
+		threshold, err := NewBoundsFromNagiosRange(arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		check := NewCheck("threshold", NewSummarizer())
+		check.AttachResources(newMockResource())
+		check.AttachContexts(NewScalarContext("usage", nil, &threshold))
+		return check, nil
+	})
+	server.Register("check_slow", func(arguments []string) (Check, error) {
+		time.Sleep(time.Second)
+		return nil, errors.New("too slow")
+	})
+	server.Register("check_panic", func(arguments []string) (Check, error) {
+		panic("invalid state")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve(listener) }()
+
+	return server, listener.Addr().String()
+}
+
+func queryNRPE(address string, version int16, command string, tlsConfig *tls.Config) (int16, string, error) {
+	connection, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return 0, "", err
+	}
+	if tlsConfig != nil {
+		connection = tls.Client(connection, tlsConfig)
+	}
+	defer connection.Close()
+	_ = connection.SetDeadline(time.Now().Add(2 * time.Second))
+
+	query := encodeNRPEPacket(nrpePacket{version: version, packetType: nrpePacketTypeQuery, buffer: command})
+	if _, err := connection.Write(query); err != nil {
+		return 0, "", err
+	}
+
+	response, err := readNRPEPacket(connection)
+	if err != nil {
+		return 0, "", err
+	}
+	if response.version != version || response.packetType != nrpePacketTypeResult {
+		return 0, "", errors.New("unexpected nrpe response packet")
+	}
+
+	return response.resultCode, response.buffer, nil
+}
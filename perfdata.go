@@ -30,13 +30,20 @@ type PerfData interface {
 	Metric() Metric
 }
 
+// ThresholdPerfData provides access to the thresholds of PerfData, e.g. for renderers exporting them as separate values
+type ThresholdPerfData interface {
+	PerfData
+	WarningThreshold() OptionalBounds
+	CriticalThreshold() OptionalBounds
+}
+
 type perfData struct {
 	metric            Metric
 	warningThreshold  OptionalBounds
 	criticalThreshold OptionalBounds
 }
 
-const illegalNameChars = "'="
+const illegalNameChars = "="
 
 // NewPerfData instantiates a new PerfData with the given metric and optional thresholds
 func NewPerfData(metric Metric, warningThreshold *Bounds, criticalThreshold *Bounds) (PerfData, error) {
@@ -93,11 +100,27 @@ func (pd perfData) Metric() Metric {
 	return pd.metric
 }
 
+func (pd perfData) WarningThreshold() OptionalBounds {
+	return pd.warningThreshold
+}
+
+func (pd perfData) CriticalThreshold() OptionalBounds {
+	return pd.criticalThreshold
+}
+
+func perfDataThresholds(perfData PerfData) (warningThreshold OptionalBounds, criticalThreshold OptionalBounds) {
+	if thresholdPerfData, ok := perfData.(ThresholdPerfData); ok {
+		return thresholdPerfData.WarningThreshold(), thresholdPerfData.CriticalThreshold()
+	}
+
+	return OptionalBounds{}, OptionalBounds{}
+}
+
 func (pd perfData) quoteString(value string) string {
 	match := regexp.MustCompile("^\\w+$").MatchString(value)
 	if match {
 		return value
 	}
 
-	return fmt.Sprintf("'%s'", value)
+	return fmt.Sprintf("'%s'", strings.Replace(value, "'", "''", -1))
 }
@@ -63,13 +63,31 @@ func TestPerfData_ToNagiosPerfData(t *testing.T) {
 	criticalThreshold := NewBounds(LowerBound(10), UpperBound(20))
 	metric1 := MustNewNumericMetric("test", 13.37, "B", &valueRange, "")
 	metric2 := MustNewNumericMetric("test with quoting", 42, "X", nil, "")
+	metric3 := MustNewNumericMetric("{host}'s latency", 42, "ms", nil, "", MetricLabel("host", "db 1"))
 
 	// when
 	perfData1, err := NewPerfData(metric1, &warningThreshold, &criticalThreshold)
 	perfData2, err := NewPerfData(metric2, nil, nil)
+	perfData3, err := NewPerfData(metric3, nil, nil)
 
 	// then
 	assert.NoError(t, err)
 	assert.Equal(t, "test=13.37B;@10:20;10:20;-100;100", perfData1.ToNagiosPerfData())
 	assert.Equal(t, "'test with quoting'=42X", perfData2.ToNagiosPerfData())
+	assert.Equal(t, "'db 1''s latency'=42ms", perfData3.ToNagiosPerfData())
+}
+
+func TestPerfData_Thresholds(t *testing.T) {
+	// given
+	warningThreshold := NewBounds(UpperBound(10))
+	metric := MustNewNumericMetric("test", 13.37, "", nil, "")
+
+	// when
+	perfData, err := NewPerfData(metric, &warningThreshold, nil)
+
+	// then
+	assert.NoError(t, err)
+	assert.Implements(t, (*ThresholdPerfData)(nil), perfData)
+	assert.Equal(t, NewOptionalBounds(warningThreshold), perfData.(ThresholdPerfData).WarningThreshold())
+	assert.Equal(t, OptionalBounds{}, perfData.(ThresholdPerfData).CriticalThreshold())
 }
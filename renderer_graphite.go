@@ -0,0 +1,132 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GraphiteRenderer converts the performance data of one or more finished Check instances into the Graphite plaintext
+// protocol. Metric paths are built from the prefix, check name and metric name, while the context and metric labels are
+// optionally attached as Graphite tags.
+type GraphiteRenderer interface {
+	Render(checks ...Check) string
+}
+
+// GraphiteRendererOpt is a type alias for functional options used by NewGraphiteRenderer()
+type GraphiteRendererOpt func(*graphiteRenderer)
+
+type graphiteRenderer struct {
+	prefix string
+	tagged bool
+}
+
+var graphiteInvalidPathChars = regexp.MustCompile(`[^a-zA-Z0-9_\-]`)
+var graphiteInvalidTagChars = regexp.MustCompile(`[;!^=~\s]`)
+
+// NewGraphiteRenderer instantiates a new GraphiteRenderer with the given functional options
+func NewGraphiteRenderer(options ...GraphiteRendererOpt) GraphiteRenderer {
+	renderer := &graphiteRenderer{
+		prefix: "nagopher",
+	}
+
+	for _, option := range options {
+		option(renderer)
+	}
+
+	return renderer
+}
+
+// GraphitePrefix is a functional option for NewGraphiteRenderer(), which sets the first path component of all metrics.
+// Dots are kept, so that the prefix may span multiple path components.
+func GraphitePrefix(prefix string) GraphiteRendererOpt {
+	return func(r *graphiteRenderer) {
+		r.prefix = prefix
+	}
+}
+
+// GraphiteTags is a functional option for NewGraphiteRenderer(), which attaches the context and metric labels as tags,
+// as supported by Graphite 1.1 and newer
+func GraphiteTags(state bool) GraphiteRendererOpt {
+	return func(r *graphiteRenderer) {
+		r.tagged = state
+	}
+}
+
+func (r graphiteRenderer) Render(checks ...Check) string {
+	var output strings.Builder
+
+	for _, check := range checks {
+		_, timestamp := checkExecutionTimes(check)
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+
+		for _, perfData := range check.PerfData() {
+			numericMetric, ok := perfData.Metric().(NumericMetric)
+			if !ok || math.IsNaN(numericMetric.Value()) || math.IsInf(numericMetric.Value(), 0) {
+				continue
+			}
+
+			fmt.Fprintf(&output, "%s %s %d\n", r.metricPath(check, numericMetric),
+				strconv.FormatFloat(numericMetric.Value(), 'f', -1, strconv.IntSize), timestamp.Unix())
+		}
+	}
+
+	return output.String()
+}
+
+func (r graphiteRenderer) metricPath(check Check, metric NumericMetric) string {
+	var components []string
+	if r.prefix != "" {
+		components = append(components, r.prefix)
+	}
+	components = append(components, sanitizeGraphitePathComponent(check.Name()),
+		sanitizeGraphitePathComponent(metric.Name()))
+
+	path := strings.Join(components, ".")
+	if !r.tagged {
+		return path
+	}
+
+	tags := metricLabels(metric).Copy()
+	tags["context"] = metric.ContextName()
+	for _, key := range tags.Keys() {
+		if tags[key] != "" {
+			path += ";" + graphiteInvalidTagChars.ReplaceAllString(key, "_") + "=" +
+				graphiteInvalidTagChars.ReplaceAllString(tags[key], "_")
+		}
+	}
+
+	return path
+}
+
+func sanitizeGraphitePathComponent(component string) string {
+	component = graphiteInvalidPathChars.ReplaceAllString(component, "_")
+	if component == "" {
+		component = "_"
+	}
+
+	return component
+}
@@ -0,0 +1,84 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGraphiteRenderer_Render(t *testing.T) {
+	// given
+	defer mockCheckTimeFunction(time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC), 250*time.Millisecond)()
+
+	check := NewCheck("disk usage", NewSummarizer())
+	check.AttachResources(newMockLabeledResource())
+	check.AttachContexts(NewScalarContext("disk", nil, nil))
+	check.Run(NewWarningCollection())
+
+	// when
+	output1 := NewGraphiteRenderer().Render(check)
+	output2 := NewGraphiteRenderer(GraphitePrefix("servers.web01"), GraphiteTags(true)).Render(check)
+	output3 := NewGraphiteRenderer(GraphitePrefix("")).Render(check)
+
+	// then
+	assert.Equal(t, strings.Join([]string{
+		"nagopher.disk_usage._tmp_used 60 1561118400",
+		"nagopher.disk_usage._var_used 60 1561118400",
+	}, "\n")+"\n", output1)
+	assert.Equal(t, strings.Join([]string{
+		"servers.web01.disk_usage._tmp_used;context=disk;mount=/tmp 60 1561118400",
+		"servers.web01.disk_usage._var_used;context=disk;mount=/var 60 1561118400",
+	}, "\n")+"\n", output2)
+	assert.True(t, strings.HasPrefix(output3, "disk_usage._tmp_used 60 "))
+}
+
+type mockUnfinishedCheck struct {
+	TimedCheck
+}
+
+func (c mockUnfinishedCheck) ExecutionEnd() time.Time {
+	return time.Time{}
+}
+
+func TestGraphiteRenderer_Render_ZeroTimestamp(t *testing.T) {
+	// given
+	check := NewCheck("disk usage", NewSummarizer())
+	check.AttachResources(newMockLabeledResource())
+	check.AttachContexts(NewScalarContext("disk", nil, nil))
+	check.Run(NewWarningCollection())
+
+	// when
+	before := time.Now().Unix()
+	output := NewGraphiteRenderer().Render(mockUnfinishedCheck{check.(TimedCheck)})
+	fields := strings.Fields(strings.SplitN(output, "\n", 2)[0])
+	timestamp, err := strconv.ParseInt(fields[len(fields)-1], 10, 64)
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, timestamp >= before)
+}
+
+func TestSanitizeGraphitePathComponent(t *testing.T) {
+	assert.Equal(t, "disk_used_-_var", sanitizeGraphitePathComponent("disk.used -/var"))
+	assert.Equal(t, "_", sanitizeGraphitePathComponent(""))
+}
@@ -0,0 +1,146 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// InfluxDBRenderer converts the performance data of one or more finished Check instances into the InfluxDB line
+// protocol. Each metric becomes its own measurement, tagged with the check name, context, unit and metric labels.
+type InfluxDBRenderer interface {
+	Render(checks ...Check) string
+}
+
+// InfluxDBRendererOpt is a type alias for functional options used by NewInfluxDBRenderer()
+type InfluxDBRendererOpt func(*influxDBRenderer)
+
+type influxDBRenderer struct {
+	measurementPrefix string
+	tags              Labels
+}
+
+var influxDBMeasurementReplacer = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+var influxDBKeyReplacer = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+
+// NewInfluxDBRenderer instantiates a new InfluxDBRenderer with the given functional options
+func NewInfluxDBRenderer(options ...InfluxDBRendererOpt) InfluxDBRenderer {
+	renderer := &influxDBRenderer{
+		tags: Labels{},
+	}
+
+	for _, option := range options {
+		option(renderer)
+	}
+
+	return renderer
+}
+
+// InfluxDBMeasurementPrefix is a functional option for NewInfluxDBRenderer(), which prefixes all measurement names
+func InfluxDBMeasurementPrefix(prefix string) InfluxDBRendererOpt {
+	return func(r *influxDBRenderer) {
+		r.measurementPrefix = prefix
+	}
+}
+
+// InfluxDBTag is a functional option for NewInfluxDBRenderer(), which adds a static tag to all points, e.g. the host
+func InfluxDBTag(key string, value string) InfluxDBRendererOpt {
+	return func(r *influxDBRenderer) {
+		r.tags[key] = value
+	}
+}
+
+func (r influxDBRenderer) Render(checks ...Check) string {
+	var output strings.Builder
+
+	for _, check := range checks {
+		for _, perfData := range check.PerfData() {
+			if line := r.renderPerfData(check, perfData); line != "" {
+				output.WriteString(line + "\n")
+			}
+		}
+	}
+
+	return output.String()
+}
+
+func (r influxDBRenderer) renderPerfData(check Check, perfData PerfData) string {
+	numericMetric, ok := perfData.Metric().(NumericMetric)
+	if !ok || math.IsNaN(numericMetric.Value()) || math.IsInf(numericMetric.Value(), 0) {
+		return ""
+	}
+
+	tags := r.tags.Copy()
+	for key, value := range metricLabels(numericMetric) {
+		tags[key] = value
+	}
+	tags["check"] = check.Name()
+	tags["context"] = numericMetric.ContextName()
+	tags["unit"] = numericMetric.ValueUnit()
+
+	var line strings.Builder
+	line.WriteString(influxDBMeasurementReplacer.Replace(r.measurementPrefix + numericMetric.Name()))
+	for _, key := range tags.Keys() {
+		if tags[key] != "" {
+			line.WriteString("," + influxDBKeyReplacer.Replace(key) + "=" + influxDBKeyReplacer.Replace(tags[key]))
+		}
+	}
+
+	fields := []string{"value=" + formatInfluxDBValue(numericMetric.Value())}
+	warningThreshold, criticalThreshold := perfDataThresholds(perfData)
+	thresholds := []struct {
+		kind   string
+		bounds OptionalBounds
+	}{
+		{"warning", warningThreshold},
+		{"critical", criticalThreshold},
+	}
+
+	for _, threshold := range thresholds {
+		bounds, err := threshold.bounds.Get()
+		if err != nil {
+			continue
+		}
+
+		for _, bound := range []string{"lower", "upper"} {
+			value, err := bounds.Lower().Get()
+			if bound == "upper" {
+				value, err = bounds.Upper().Get()
+			}
+			if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+				continue
+			}
+
+			fields = append(fields, threshold.kind+"_"+bound+"="+formatInfluxDBValue(value))
+		}
+	}
+
+	line.WriteString(" " + strings.Join(fields, ","))
+	if _, executionEnd := checkExecutionTimes(check); !executionEnd.IsZero() {
+		line.WriteString(" " + strconv.FormatInt(executionEnd.UnixNano(), 10))
+	}
+
+	return line.String()
+}
+
+func formatInfluxDBValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, strconv.IntSize)
+}
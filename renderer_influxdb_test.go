@@ -0,0 +1,64 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfluxDBRenderer_Render(t *testing.T) {
+	// given
+	defer mockCheckTimeFunction(time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC), 250*time.Millisecond)()
+
+	warningThreshold, _ := NewBoundsFromNagiosRange("50")
+	criticalThreshold, _ := NewBoundsFromNagiosRange("10:90")
+	check := NewCheck("disk", NewSummarizer())
+	check.AttachResources(newMockLabeledResource())
+	check.AttachContexts(NewScalarContext("disk", &warningThreshold, &criticalThreshold))
+	check.Run(NewWarningCollection())
+
+	// when
+	output := NewInfluxDBRenderer(InfluxDBMeasurementPrefix("nagopher "), InfluxDBTag("host", "web01")).Render(check)
+
+	// then
+	assert.Equal(t, strings.Join([]string{
+		`nagopher\ /tmp_used,check=disk,context=disk,host=web01,mount=/tmp,unit=% value=60,warning_lower=0,warning_upper=50,critical_lower=10,critical_upper=90 1561118400250000000`,
+		`nagopher\ /var_used,check=disk,context=disk,host=web01,mount=/var,unit=% value=60,warning_lower=0,warning_upper=50,critical_lower=10,critical_upper=90 1561118400250000000`,
+	}, "\n")+"\n", output)
+}
+
+func TestInfluxDBRenderer_Render_Escaping(t *testing.T) {
+	// given
+	check := NewCheck("usage, total", NewSummarizer())
+	check.AttachResources(newMockDaemonResource())
+	check.AttachContexts(NewScalarContext("usage", nil, nil))
+	check.Run(NewWarningCollection())
+
+	// when
+	output := NewInfluxDBRenderer(InfluxDBTag("a=b", "")).Render(check, NewCheck("empty", NewSummarizer()))
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+
+	// then
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `usage1,check=usage\,\ total,context=usage,unit=% value=49.4 `))
+	assert.True(t, strings.HasPrefix(lines[1], `usage2,check=usage\,\ total,context=usage,unit=% value=92.6 `))
+}
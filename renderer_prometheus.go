@@ -0,0 +1,224 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PrometheusRenderer converts one or more finished Check instances into the Prometheus text exposition format, which
+// allows exporting the collected performance data, thresholds and states to Prometheus.
+type PrometheusRenderer interface {
+	Render(checks ...Check) string
+}
+
+// PrometheusRendererOpt is a type alias for functional options used by NewPrometheusRenderer()
+type PrometheusRendererOpt func(*prometheusRenderer)
+
+type prometheusRenderer struct {
+	namespace   string
+	openMetrics bool
+}
+
+type prometheusFamily struct {
+	name    string
+	help    string
+	samples []string
+}
+
+type prometheusFamilies struct {
+	names    []string
+	families map[string]*prometheusFamily
+}
+
+var prometheusInvalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+var prometheusInvalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+var prometheusLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// NewPrometheusRenderer instantiates a new PrometheusRenderer with the given functional options
+func NewPrometheusRenderer(options ...PrometheusRendererOpt) PrometheusRenderer {
+	renderer := &prometheusRenderer{
+		namespace: "nagopher",
+	}
+
+	for _, option := range options {
+		option(renderer)
+	}
+
+	return renderer
+}
+
+// PrometheusNamespace is a functional option for NewPrometheusRenderer(), which sets the prefix of all metric names
+func PrometheusNamespace(namespace string) PrometheusRendererOpt {
+	return func(r *prometheusRenderer) {
+		r.namespace = namespace
+	}
+}
+
+// PrometheusOpenMetrics is a functional option for NewPrometheusRenderer(), which terminates the output with an EOF
+// marker as mandated by the OpenMetrics specification
+func PrometheusOpenMetrics(state bool) PrometheusRendererOpt {
+	return func(r *prometheusRenderer) {
+		r.openMetrics = state
+	}
+}
+
+func (r prometheusRenderer) Render(checks ...Check) string {
+	families := &prometheusFamilies{families: make(map[string]*prometheusFamily)}
+
+	for _, check := range checks {
+		checkLabels := Labels{"check": check.Name()}
+
+		families.add(r.metricName("check_state"), "Exit code of the check (0=OK, 1=WARNING, 2=CRITICAL, 3=UNKNOWN)",
+			checkLabels, float64(check.State().ExitCode()))
+		executionStart, executionEnd := checkExecutionTimes(check)
+		families.add(r.metricName("check_duration_seconds"), "Duration of the last check execution in seconds",
+			checkLabels, executionEnd.Sub(executionStart).Seconds())
+
+		for _, perfData := range check.PerfData() {
+			r.renderPerfData(families, check, perfData)
+		}
+	}
+
+	var output strings.Builder
+	for _, name := range families.names {
+		family := families.families[name]
+		if family.help != "" {
+			fmt.Fprintf(&output, "# HELP %s %s\n", family.name, family.help)
+		}
+		fmt.Fprintf(&output, "# TYPE %s gauge\n", family.name)
+		for _, sample := range family.samples {
+			output.WriteString(sample + "\n")
+		}
+	}
+
+	if r.openMetrics {
+		output.WriteString("# EOF\n")
+	}
+
+	return output.String()
+}
+
+func (r prometheusRenderer) renderPerfData(families *prometheusFamilies, check Check, perfData PerfData) {
+	numericMetric, ok := perfData.Metric().(NumericMetric)
+	if !ok {
+		return
+	}
+
+	labels := Labels{}
+	for key, value := range metricLabels(numericMetric) {
+		labels[sanitizePrometheusLabelName(key)] = value
+	}
+	labels["check"] = check.Name()
+	labels["context"] = numericMetric.ContextName()
+
+	name := r.metricName(numericMetric.Name())
+	families.add(name, "", labels, numericMetric.Value())
+
+	warningThreshold, criticalThreshold := perfDataThresholds(perfData)
+	thresholds := []struct {
+		kind   string
+		bounds OptionalBounds
+	}{
+		{"warning", warningThreshold},
+		{"critical", criticalThreshold},
+	}
+
+	for _, threshold := range thresholds {
+		bounds, err := threshold.bounds.Get()
+		if err != nil {
+			continue
+		}
+
+		for _, bound := range []string{"lower", "upper"} {
+			value, err := bounds.Lower().Get()
+			if bound == "upper" {
+				value, err = bounds.Upper().Get()
+			}
+			if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+				continue
+			}
+
+			thresholdLabels := labels.Copy()
+			thresholdLabels["type"] = threshold.kind
+			thresholdLabels["bound"] = bound
+			families.add(name+"_threshold", "", thresholdLabels, value)
+		}
+	}
+}
+
+func (r prometheusRenderer) metricName(name string) string {
+	if r.namespace != "" {
+		name = r.namespace + "_" + name
+	}
+
+	return sanitizePrometheusMetricName(name)
+}
+
+func (f *prometheusFamilies) add(name string, help string, labels Labels, value float64) {
+	family, ok := f.families[name]
+	if !ok {
+		family = &prometheusFamily{name: name, help: help}
+		f.families[name] = family
+		f.names = append(f.names, name)
+	}
+
+	labelParts := make([]string, 0, len(labels))
+	for _, key := range labels.Keys() {
+		labelParts = append(labelParts, fmt.Sprintf(`%s="%s"`, key, prometheusLabelValueReplacer.Replace(labels[key])))
+	}
+
+	family.samples = append(family.samples, fmt.Sprintf("%s{%s} %s", name, strings.Join(labelParts, ","),
+		formatPrometheusValue(value)))
+}
+
+func sanitizePrometheusMetricName(name string) string {
+	name = prometheusInvalidNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	return name
+}
+
+func sanitizePrometheusLabelName(name string) string {
+	name = prometheusInvalidLabelChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	return name
+}
+
+func formatPrometheusValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, strconv.IntSize)
+}
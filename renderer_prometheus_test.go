@@ -0,0 +1,105 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusRenderer_Render(t *testing.T) {
+	// given
+	defer mockCheckTimeFunction(time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC), 250*time.Millisecond)()
+
+	warningThreshold, _ := NewBoundsFromNagiosRange("50")
+	criticalThreshold, _ := NewBoundsFromNagiosRange("10:90")
+	check := NewCheck("disk", NewSummarizer())
+	check.AttachResources(newMockLabeledResource())
+	check.AttachContexts(NewScalarContext("disk", &warningThreshold, &criticalThreshold))
+	check.Run(NewWarningCollection())
+
+	// when
+	output := NewPrometheusRenderer().Render(check)
+
+	// then
+	assert.Equal(t, strings.Join([]string{
+		"# HELP nagopher_check_state Exit code of the check (0=OK, 1=WARNING, 2=CRITICAL, 3=UNKNOWN)",
+		"# TYPE nagopher_check_state gauge",
+		`nagopher_check_state{check="disk"} 1`,
+		"# HELP nagopher_check_duration_seconds Duration of the last check execution in seconds",
+		"# TYPE nagopher_check_duration_seconds gauge",
+		`nagopher_check_duration_seconds{check="disk"} 0.25`,
+		"# TYPE nagopher__tmp_used gauge",
+		`nagopher__tmp_used{check="disk",context="disk",mount="/tmp"} 60`,
+		"# TYPE nagopher__tmp_used_threshold gauge",
+		`nagopher__tmp_used_threshold{bound="lower",check="disk",context="disk",mount="/tmp",type="warning"} 0`,
+		`nagopher__tmp_used_threshold{bound="upper",check="disk",context="disk",mount="/tmp",type="warning"} 50`,
+		`nagopher__tmp_used_threshold{bound="lower",check="disk",context="disk",mount="/tmp",type="critical"} 10`,
+		`nagopher__tmp_used_threshold{bound="upper",check="disk",context="disk",mount="/tmp",type="critical"} 90`,
+		"# TYPE nagopher__var_used gauge",
+		`nagopher__var_used{check="disk",context="disk",mount="/var"} 60`,
+		"# TYPE nagopher__var_used_threshold gauge",
+		`nagopher__var_used_threshold{bound="lower",check="disk",context="disk",mount="/var",type="warning"} 0`,
+		`nagopher__var_used_threshold{bound="upper",check="disk",context="disk",mount="/var",type="warning"} 50`,
+		`nagopher__var_used_threshold{bound="lower",check="disk",context="disk",mount="/var",type="critical"} 10`,
+		`nagopher__var_used_threshold{bound="upper",check="disk",context="disk",mount="/var",type="critical"} 90`,
+	}, "\n")+"\n", output)
+}
+
+func TestPrometheusRenderer_Render_Options(t *testing.T) {
+	// given
+	check1 := NewCheck("first check", NewSummarizer())
+	check2 := NewCheck("second \"check\"", NewSummarizer())
+
+	// when
+	output := NewPrometheusRenderer(PrometheusNamespace("plugin"), PrometheusOpenMetrics(true)).Render(check1, check2)
+
+	// then
+	assert.Contains(t, output, "plugin_check_state{check=\"first check\"} 3\nplugin_check_state{check=\"second \\\"check\\\"\"} 3\n")
+	assert.True(t, strings.HasSuffix(output, "# EOF\n"))
+}
+
+func TestSanitizePrometheusNames(t *testing.T) {
+	assert.Equal(t, "nagopher_disk_used:total", sanitizePrometheusMetricName("nagopher_disk-used:total"))
+	assert.Equal(t, "_1st_metric", sanitizePrometheusMetricName("1st metric"))
+	assert.Equal(t, "mount_point", sanitizePrometheusLabelName("mount:point"))
+	assert.Equal(t, "_0", sanitizePrometheusLabelName("0"))
+}
+
+func TestFormatPrometheusValue(t *testing.T) {
+	assert.Equal(t, "13.37", formatPrometheusValue(13.37))
+	assert.Equal(t, "NaN", formatPrometheusValue(math.NaN()))
+	assert.Equal(t, "+Inf", formatPrometheusValue(math.Inf(1)))
+	assert.Equal(t, "-Inf", formatPrometheusValue(math.Inf(-1)))
+}
+
+func mockCheckTimeFunction(start time.Time, step time.Duration) func() {
+	current := start.Add(-step)
+	checkTimeFunction = func() time.Time {
+		current = current.Add(step)
+		return current
+	}
+
+	return func() {
+		checkTimeFunction = time.Now
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// MetricRenderer is implemented by all renderers, which convert finished checks into a text based metric format, e.g.
+// InfluxDBRenderer or GraphiteRenderer
+type MetricRenderer interface {
+	Render(checks ...Check) string
+}
+
+// PushMetrics renders the given checks and sends the result to a time series database listening at the given address.
+// The network must either be "tcp" or "udp", in which case every line is sent as a separate datagram to stay below
+// the maximum datagram size. A timeout of zero disables the timeout.
+func PushMetrics(network string, address string, timeout time.Duration, renderer MetricRenderer, checks ...Check) error {
+	payload := renderer.Render(checks...)
+	if payload == "" {
+		return nil
+	}
+
+	connection, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return fmt.Errorf("nagopher: could not connect to metric receiver (%s)", err.Error())
+	}
+	defer connection.Close()
+	if timeout > 0 {
+		_ = connection.SetDeadline(time.Now().Add(timeout))
+	}
+
+	var packets []string
+	if strings.HasPrefix(network, "udp") {
+		for _, line := range strings.SplitAfter(payload, "\n") {
+			if line != "" {
+				packets = append(packets, line)
+			}
+		}
+	} else {
+		packets = []string{payload}
+	}
+
+	for _, packet := range packets {
+		if _, err := connection.Write([]byte(packet)); err != nil {
+			return fmt.Errorf("nagopher: could not push metrics (%s)", err.Error())
+		}
+	}
+
+	return nil
+}
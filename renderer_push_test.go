@@ -0,0 +1,128 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPushMetrics_TCP(t *testing.T) {
+	// given
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			received <- ""
+			return
+		}
+		defer connection.Close()
+
+		data, _ := ioutil.ReadAll(connection)
+		received <- string(data)
+	}()
+
+	check := newMockDaemonCheck()
+	check.Run(NewWarningCollection())
+	renderer := NewGraphiteRenderer()
+
+	// when
+	err = PushMetrics("tcp", listener.Addr().String(), time.Second, renderer, check)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, renderer.Render(check), <-received)
+}
+
+func TestPushMetrics_NoTimeout(t *testing.T) {
+	// given
+	connection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer connection.Close()
+
+	check := newMockDaemonCheck()
+	check.Run(NewWarningCollection())
+
+	// when
+	err = PushMetrics("udp", connection.LocalAddr().String(), 0, NewGraphiteRenderer(), check)
+
+	// then
+	assert.NoError(t, err)
+}
+
+func TestPushMetrics_UDP(t *testing.T) {
+	// given
+	connection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer connection.Close()
+
+	check := newMockDaemonCheck()
+	check.Run(NewWarningCollection())
+	renderer := NewInfluxDBRenderer()
+
+	// when
+	err = PushMetrics("udp", connection.LocalAddr().String(), time.Second, renderer, check)
+
+	var datagrams []string
+	buffer := make([]byte, 65536)
+	_ = connection.SetReadDeadline(time.Now().Add(time.Second))
+	for len(datagrams) < 2 {
+		length, _, err := connection.ReadFrom(buffer)
+		if err != nil {
+			break
+		}
+		datagrams = append(datagrams, string(buffer[:length]))
+	}
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(datagrams))
+	assert.Equal(t, renderer.Render(check), datagrams[0]+datagrams[1])
+}
+
+func TestPushMetrics_Errors(t *testing.T) {
+	// given
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	check := newMockDaemonCheck()
+	check.Run(NewWarningCollection())
+
+	// when
+	err1 := PushMetrics("tcp", address, time.Second, NewGraphiteRenderer(), check)
+	err2 := PushMetrics("tcp", address, time.Second, NewGraphiteRenderer())
+
+	// then
+	assert.Error(t, err1)
+	assert.NoError(t, err2)
+}
@@ -0,0 +1,82 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"time"
+)
+
+type checkReport struct {
+	Name           string         `json:"name"`
+	State          string         `json:"state"`
+	ExitCode       int8           `json:"exit_code"`
+	Output         string         `json:"output"`
+	Summary        string         `json:"summary"`
+	PerfData       []string       `json:"perfdata"`
+	Results        []resultReport `json:"results"`
+	ExecutionStart time.Time      `json:"execution_start"`
+	ExecutionEnd   time.Time      `json:"execution_end"`
+}
+
+type resultReport struct {
+	State    string `json:"state"`
+	Text     string `json:"text"`
+	Hint     string `json:"hint,omitempty"`
+	Label    string `json:"label,omitempty"`
+	Metric   string `json:"metric,omitempty"`
+	Context  string `json:"context,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
+
+func newCheckReport(check Check, checkResult CheckResult) checkReport {
+	executionStart, executionEnd := checkExecutionTimes(check)
+	report := checkReport{
+		Name:           check.Name(),
+		State:          check.State().Description(),
+		ExitCode:       checkResult.ExitCode(),
+		Output:         checkResult.Output(),
+		Summary:        check.Summary(),
+		PerfData:       []string{},
+		Results:        []resultReport{},
+		ExecutionStart: executionStart,
+		ExecutionEnd:   executionEnd,
+	}
+
+	for _, perfData := range check.PerfData() {
+		report.PerfData = append(report.PerfData, perfData.ToNagiosPerfData())
+	}
+
+	for _, result := range check.Results().Get() {
+		resultReport := resultReport{
+			State: StateInfo().Description(),
+			Text:  result.String(),
+			Hint:  result.Hint(),
+			Label: resultLabel(result),
+		}
+
+		result.State().If(func(state State) { resultReport.State = state.Description() })
+		result.Metric().If(func(metric Metric) { resultReport.Metric = metric.Name() })
+		result.Context().If(func(context Context) { resultReport.Context = context.Name() })
+		result.Resource().If(func(resource Resource) { resultReport.Resource, _ = resourceName(resource) })
+
+		report.Results = append(report.Results, resultReport)
+	}
+
+	return report
+}
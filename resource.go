@@ -18,6 +18,11 @@
 
 package nagopher
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // Resource offers a method for collecting one or more metrics
 type Resource interface {
 	Setup(WarningCollection) error
@@ -25,13 +30,59 @@ type Resource interface {
 	Teardown(WarningCollection) error
 }
 
-type baseResource struct{}
+// NamedResource is an optional extension of Resource, which provides a human-readable name. The name is used to
+// identify the resource within error hints, verbose output and JSON reports instead of its Go type.
+type NamedResource interface {
+	Resource
+
+	Name() string
+}
+
+// ResourcePhase represents a single phase of the resource lifecycle, which is executed by Check.Run()
+type ResourcePhase string
+
+// Resource lifecycle phases, which are used for labelling errors. Teardown always runs after a successful setup, even
+// if probing or evaluating the metrics of a resource failed.
+const (
+	ResourcePhaseSetup    ResourcePhase = "setup"
+	ResourcePhaseProbe    ResourcePhase = "probe"
+	ResourcePhaseEvaluate ResourcePhase = "evaluate"
+	ResourcePhaseTeardown ResourcePhase = "teardown"
+)
+
+// ResourceError represents an error, which occurred during a specific lifecycle phase of a resource
+type ResourceError interface {
+	error
+
+	Phase() ResourcePhase
+	Resource() Resource
+	Cause() error
+}
+
+type baseResource struct {
+	name string
+}
+
+type resourceError struct {
+	phase    ResourcePhase
+	resource Resource
+	cause    error
+}
 
 // NewResource instantiates a new Resource.
 func NewResource() Resource {
 	return &baseResource{}
 }
 
+// NewNamedResource instantiates a new NamedResource with the given name, which can be embedded into custom resources
+func NewNamedResource(name string) NamedResource {
+	return &baseResource{name: name}
+}
+
+func (r baseResource) Name() string {
+	return r.name
+}
+
 func (r baseResource) Probe(warnings WarningCollection) ([]Metric, error) {
 	return []Metric{}, nil
 }
@@ -43,3 +94,46 @@ func (r baseResource) Setup(warnings WarningCollection) error {
 func (r baseResource) Teardown(warnings WarningCollection) error {
 	return nil
 }
+
+func newResourceError(phase ResourcePhase, resource Resource, cause error) ResourceError {
+	return &resourceError{
+		phase:    phase,
+		resource: resource,
+		cause:    cause,
+	}
+}
+
+func (e resourceError) Error() string {
+	return fmt.Sprintf("%s: %s", e.phase, e.cause.Error())
+}
+
+func (e resourceError) Phase() ResourcePhase {
+	return e.phase
+}
+
+func (e resourceError) Resource() Resource {
+	return e.resource
+}
+
+func (e resourceError) Cause() error {
+	return e.cause
+}
+
+func resourceName(resource Resource) (string, bool) {
+	if namedResource, ok := resource.(NamedResource); ok && namedResource.Name() != "" {
+		return namedResource.Name(), true
+	}
+
+	return reflect.TypeOf(resource).String(), false
+}
+
+// isSameResource compares pointer resources by identity. Value types are never considered the same, as comparing them
+// could panic at runtime, e.g. when they embed an interface holding an uncomparable value.
+func isSameResource(resource1 Resource, resource2 Resource) bool {
+	resourceType := reflect.TypeOf(resource1)
+	if resourceType != reflect.TypeOf(resource2) || resourceType.Kind() != reflect.Ptr {
+		return false
+	}
+
+	return reflect.ValueOf(resource1).Pointer() == reflect.ValueOf(resource2).Pointer()
+}
@@ -36,3 +36,51 @@ func TestBaseResource_Probe(t *testing.T) {
 	assert.Empty(t, warnings.Get())
 	assert.Empty(t, metrics)
 }
+
+func TestNewNamedResource(t *testing.T) {
+	// when
+	resource := NewNamedResource("database")
+
+	// then
+	assert.Implements(t, (*Resource)(nil), resource)
+	assert.Equal(t, "database", resource.Name())
+}
+
+func TestResourceName(t *testing.T) {
+	// when
+	name1, ok1 := resourceName(NewNamedResource("database"))
+	name2, ok2 := resourceName(NewNamedResource(""))
+	name3, ok3 := resourceName(newMockResource())
+
+	// then
+	assert.Equal(t, "database", name1)
+	assert.True(t, ok1)
+	assert.Equal(t, "*nagopher.baseResource", name2)
+	assert.False(t, ok2)
+	assert.Equal(t, "*nagopher.mockResource", name3)
+	assert.False(t, ok3)
+}
+
+func TestIsSameResource(t *testing.T) {
+	// given
+	resource1 := NewResource()
+	resource2 := NewResource()
+	uncomparableResource := mockUncomparableResource{Resource: resource1}
+	wrappingResource := mockWrappingResource{Resource: uncomparableResource}
+
+	// then
+	assert.True(t, isSameResource(resource1, resource1))
+	assert.False(t, isSameResource(resource1, resource2))
+	assert.False(t, isSameResource(resource1, uncomparableResource))
+	assert.False(t, isSameResource(uncomparableResource, uncomparableResource))
+	assert.False(t, isSameResource(wrappingResource, wrappingResource))
+}
+
+type mockWrappingResource struct {
+	Resource
+}
+
+type mockUncomparableResource struct {
+	Resource
+	metrics []Metric
+}
@@ -33,11 +33,18 @@ type Result interface {
 	Resource() OptionalResource
 }
 
+// LabeledResult is a Result labeled with the context which produced it, when a metric got evaluated by several contexts
+type LabeledResult interface {
+	Result
+	Label() string
+}
+
 // ResultOpt is a type alias for functional options used by NewResult()
 type ResultOpt func(*result)
 
 type result struct {
 	hint     string
+	label    string
 	state    OptionalState
 	metric   OptionalMetric
 	context  OptionalContext
@@ -62,6 +69,14 @@ func ResultHint(value string) ResultOpt {
 	}
 }
 
+// ResultLabel is a functional option for NewResult(), which stores a label distinguishing the result from other results
+// of the same metric, e.g. when a metric gets evaluated by multiple contexts
+func ResultLabel(value string) ResultOpt {
+	return func(r *result) {
+		r.label = value
+	}
+}
+
 // ResultState is a functional option for NewResult(), which stores the state of the result
 func ResultState(value State) ResultOpt {
 	return func(r *result) {
@@ -99,6 +114,10 @@ func ResultResource(value Resource) ResultOpt {
 }
 
 func (r result) String() string {
+	return labelResultDescription(r.label, r.describe())
+}
+
+func (r result) describe() string {
 	var description string
 
 	if metric, err := r.metric.Get(); err == nil {
@@ -122,6 +141,10 @@ func (r result) Hint() string {
 	return r.hint
 }
 
+func (r result) Label() string {
+	return r.label
+}
+
 func (r result) State() OptionalState {
 	return r.state
 }
@@ -137,3 +160,37 @@ func (r result) Context() OptionalContext {
 func (r result) Resource() OptionalResource {
 	return r.resource
 }
+
+type labeledResult struct {
+	Result
+	label string
+}
+
+// withResultLabel returns a copy of the given result, which carries the given label in addition to all other fields
+func withResultLabel(result Result, label string) Result {
+	return &labeledResult{Result: result, label: label}
+}
+
+func (r labeledResult) String() string {
+	return labelResultDescription(r.label, r.Result.String())
+}
+
+func (r labeledResult) Label() string {
+	return r.label
+}
+
+func resultLabel(result Result) string {
+	if labeledResult, ok := result.(LabeledResult); ok {
+		return labeledResult.Label()
+	}
+
+	return ""
+}
+
+func labelResultDescription(label string, description string) string {
+	if label == "" || description == "" {
+		return description
+	}
+
+	return fmt.Sprintf("%s: %s", label, description)
+}
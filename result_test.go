@@ -67,3 +67,22 @@ func TestResult_String(t *testing.T) {
 	assert.Equal(t, "metric is 13.37 (Result Hint)", result3.String())
 	assert.Equal(t, "13.37 (Result Hint)", result4.String())
 }
+
+func TestResult_Label(t *testing.T) {
+	// given
+	metric := MustNewNumericMetric("metric", 13.37, "", nil, "")
+	context := NewScalarContext("context", nil, nil)
+
+	// when
+	result1 := NewResult(ResultContext(context), ResultMetric(metric), ResultLabel("absolute"))
+	result2 := withResultLabel(NewResult(ResultContext(context), ResultMetric(metric), ResultHint("Hint")), "delta")
+	result3 := NewResult(ResultLabel("empty"))
+
+	// then
+	assert.Equal(t, "absolute", resultLabel(result1))
+	assert.Equal(t, "absolute: metric is 13.37", result1.String())
+	assert.Equal(t, "delta", resultLabel(result2))
+	assert.Equal(t, "Hint", result2.Hint())
+	assert.Equal(t, "delta: metric is 13.37 (Hint)", result2.String())
+	assert.Equal(t, "", result3.String())
+}
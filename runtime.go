@@ -21,6 +21,7 @@ package nagopher
 import (
 	"fmt"
 	"os"
+	"runtime/debug"
 	"strings"
 )
 
@@ -36,8 +37,11 @@ type CheckResult interface {
 	Output() string
 }
 
+// RuntimeOpt is a type alias for functional options used by NewRuntime()
+type RuntimeOpt func(*baseRuntime)
+
 type baseRuntime struct {
-	verboseOutput bool
+	verbosity int
 }
 
 type checkResult struct {
@@ -50,16 +54,36 @@ var resultExitFunction = os.Exit
 var illegalOutputChars = []string{"|", "\n"}
 
 // NewRuntime instantiates a new Runtime, optionally enabling verbose output
-func NewRuntime(verboseOutput bool) Runtime {
-	runtime := &baseRuntime{
-		verboseOutput: verboseOutput,
+func NewRuntime(verboseOutput bool, options ...RuntimeOpt) Runtime {
+	runtime := &baseRuntime{}
+	if verboseOutput {
+		runtime.verbosity = 1
+	}
+
+	for _, option := range options {
+		option(runtime)
 	}
 
 	return runtime
 }
 
-func (r baseRuntime) Execute(check Check) CheckResult {
+// RuntimeVerbosity is a functional option for NewRuntime(), which sets the verbosity level as passed with "-v", "-vv"
+// or "-vvv" according to the Nagios plugin guidelines. Level 1 and above print the verbose summary, while level 3 also
+// prints the stack traces of recovered panics.
+func RuntimeVerbosity(level int) RuntimeOpt {
+	return func(r *baseRuntime) {
+		r.verbosity = level
+	}
+}
+
+func (r baseRuntime) Execute(check Check) (result CheckResult) {
 	warnings := NewWarningCollection()
+	defer func() {
+		if value := recover(); value != nil {
+			result = r.buildPanicResult(check, value, debug.Stack())
+		}
+	}()
+
 	check.Run(warnings)
 
 	checkState := check.State()
@@ -85,13 +109,19 @@ func (r baseRuntime) buildNagiosOutput(check Check, warnings WarningCollection)
 	}
 	outputParts = append(outputParts, "\n")
 
-	if r.verboseOutput {
+	if r.verbosity >= 1 {
 		lines := r.sanitizeStrings(check.VerboseSummary(), warnings)
 		if len(lines) > 0 {
 			outputParts = append(outputParts, strings.Join(lines, "\n"), "\n")
 		}
 	}
 
+	if diagnosticCheck, ok := check.(DiagnosticCheck); ok && r.verbosity >= 3 {
+		for _, trace := range diagnosticCheck.PanicTraces() {
+			outputParts = append(outputParts, r.formatTrace(trace))
+		}
+	}
+
 	if warningStrings := warnings.GetWarningStrings(); len(warningStrings) > 0 {
 		warningStrings = r.sanitizeStrings(warningStrings, nil)
 		outputParts = append(outputParts, strings.Join(warningStrings, "\n"), "\n")
@@ -100,6 +130,27 @@ func (r baseRuntime) buildNagiosOutput(check Check, warnings WarningCollection)
 	return strings.Join(outputParts, "")
 }
 
+func (r baseRuntime) buildPanicResult(check Check, value interface{}, stack []byte) CheckResult {
+	var outputParts []string
+	if check.Name() != "" {
+		outputParts = append(outputParts, strings.ToUpper(check.Name()))
+	}
+	outputParts = append(outputParts, strings.ToUpper(StateUnknown().Description()), "-",
+		fmt.Sprintf("nagopher: check panicked with [%v]", value))
+
+	output := strings.Join(r.sanitizeStrings(outputParts, nil), " ") + "\n"
+	if r.verbosity >= 3 {
+		output += r.formatTrace(fmt.Sprintf("check panicked with [%v]\n%s", value, stack))
+	}
+
+	return NewCheckResult(StateUnknown().ExitCode(), output)
+}
+
+func (r baseRuntime) formatTrace(trace string) string {
+	lines := strings.Split(strings.TrimRight(trace, "\n"), "\n")
+	return strings.Join(r.sanitizeStrings(lines, nil), "\n") + "\n"
+}
+
 func (r baseRuntime) buildNagiosStatus(check Check, warnings WarningCollection) string {
 	var outputParts []string
 
@@ -0,0 +1,226 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CheckmkRuntime is a Runtime, which renders checks in the output format of Checkmk local checks instead of the Nagios
+// plugin format. As Checkmk reads the state of each service from the output, multiple checks can be rendered at once.
+type CheckmkRuntime interface {
+	Runtime
+
+	ExecuteAll(checks ...Check) CheckResult
+}
+
+// CheckmkRuntimeOpt is a type alias for functional options used by NewCheckmkRuntime()
+type CheckmkRuntimeOpt func(*checkmkRuntime)
+
+type checkmkRuntime struct {
+	verboseOutput bool
+	dynamicState  bool
+}
+
+var checkmkServiceNameReplacer = strings.NewReplacer(`"`, `'`, "\n", " ")
+var checkmkMetricNameReplacer = strings.NewReplacer(" ", "_", "|", "_", "=", "_", ";", "_", "\n", "_")
+var checkmkTextReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// NewCheckmkRuntime instantiates a new CheckmkRuntime with the given functional options
+func NewCheckmkRuntime(options ...CheckmkRuntimeOpt) CheckmkRuntime {
+	runtime := &checkmkRuntime{}
+
+	for _, option := range options {
+		option(runtime)
+	}
+
+	return runtime
+}
+
+// CheckmkVerboseOutput is a functional option for NewCheckmkRuntime(), which appends the verbose summary of each check
+// as long output, using the escaped line breaks supported by Checkmk
+func CheckmkVerboseOutput(state bool) CheckmkRuntimeOpt {
+	return func(r *checkmkRuntime) {
+		r.verboseOutput = state
+	}
+}
+
+// CheckmkDynamicState is a functional option for NewCheckmkRuntime(), which emits the state "P" instead of the check
+// state. Checkmk then computes the service state on its own by comparing the performance data against its thresholds.
+func CheckmkDynamicState(state bool) CheckmkRuntimeOpt {
+	return func(r *checkmkRuntime) {
+		r.dynamicState = state
+	}
+}
+
+func (r checkmkRuntime) Execute(check Check) CheckResult {
+	return r.ExecuteAll(check)
+}
+
+// ExecuteAndExit prints the rendered local check and always exits with code 0, as Checkmk reads the state from the
+// output instead of the exit code
+func (r checkmkRuntime) ExecuteAndExit(check Check) {
+	result := r.Execute(check)
+	_, _ = resultOutputFunction(result.Output())
+	resultExitFunction(0)
+}
+
+func (r checkmkRuntime) ExecuteAll(checks ...Check) CheckResult {
+	worstState := StateOk()
+	lines := make([]string, 0, len(checks))
+
+	for _, check := range checks {
+		state, line := r.executeCheck(check)
+		if state.Priority() > worstState.Priority() {
+			worstState = state
+		}
+		lines = append(lines, line)
+	}
+
+	return NewCheckResult(worstState.ExitCode(), strings.Join(lines, ""))
+}
+
+func (r checkmkRuntime) executeCheck(check Check) (state State, line string) {
+	defer func() {
+		if value := recover(); value != nil {
+			state = StateUnknown()
+			line = strings.Join([]string{
+				strconv.Itoa(int(state.ExitCode())), quoteCheckmkServiceName(check.Name()), "-",
+				checkmkTextReplacer.Replace(fmt.Sprintf("nagopher: check panicked with [%v]", value)),
+			}, " ") + "\n"
+		}
+	}()
+
+	warnings := NewWarningCollection()
+	check.Run(warnings)
+
+	return check.State(), r.buildLocalCheckLine(check, warnings)
+}
+
+func (r checkmkRuntime) buildLocalCheckLine(check Check, warnings WarningCollection) string {
+	state := strconv.Itoa(int(check.State().ExitCode()))
+	if r.dynamicState && check.State() != StateUnknown() {
+		state = "P"
+	}
+
+	perfData := "-"
+	if check.State() != StateUnknown() {
+		if value := r.buildPerfData(check.PerfData(), warnings); value != "" {
+			perfData = value
+		}
+	}
+
+	textLines := []string{strings.TrimSpace(check.Summary())}
+	if textLines[0] == "" {
+		textLines[0] = strings.ToUpper(check.State().Description())
+	}
+	if r.verboseOutput {
+		textLines = append(textLines, check.VerboseSummary()...)
+	}
+	textLines = append(textLines, warnings.GetWarningStrings()...)
+
+	return strings.Join([]string{
+		state, quoteCheckmkServiceName(check.Name()), perfData,
+		checkmkTextReplacer.Replace(strings.Join(textLines, "\n")),
+	}, " ") + "\n"
+}
+
+func (r checkmkRuntime) buildPerfData(perfData []PerfData, warnings WarningCollection) string {
+	var parts []string
+
+	for _, value := range perfData {
+		numericMetric, ok := value.Metric().(NumericMetric)
+		if !ok {
+			continue
+		}
+
+		warningThreshold, criticalThreshold := perfDataThresholds(value)
+		fields := []string{
+			formatCheckmkValue(numericMetric.Value()),
+			formatCheckmkThreshold(warningThreshold, numericMetric, warnings),
+			formatCheckmkThreshold(criticalThreshold, numericMetric, warnings),
+			"", "",
+		}
+		if valueRange, err := numericMetric.ValueRange().Get(); err == nil {
+			fields[3] = formatCheckmkBound(valueRange.Lower().Get())
+			fields[4] = formatCheckmkBound(valueRange.Upper().Get())
+		}
+
+		for len(fields) > 1 && fields[len(fields)-1] == "" {
+			fields = fields[:len(fields)-1]
+		}
+
+		name := checkmkMetricNameReplacer.Replace(numericMetric.Name())
+		parts = append(parts, name+"="+strings.Join(fields, ";"))
+	}
+
+	return strings.Join(parts, "|")
+}
+
+func quoteCheckmkServiceName(name string) string {
+	if name == "" {
+		name = "nagopher"
+	}
+
+	return `"` + checkmkServiceNameReplacer.Replace(name) + `"`
+}
+
+func formatCheckmkThreshold(optionalBounds OptionalBounds, metric Metric, warnings WarningCollection) string {
+	bounds, err := optionalBounds.Get()
+	if err != nil {
+		return ""
+	}
+
+	if bounds.IsInverted() {
+		warnings.Add(NewWarning("nagopher: inverted threshold [%s] of metric [%s] is not supported by checkmk",
+			bounds.ToNagiosRange(), metric.Name()))
+		return ""
+	}
+
+	lower := formatCheckmkBound(bounds.Lower().Get())
+	upper := formatCheckmkBound(bounds.Upper().Get())
+	if upper == "" {
+		if lower != "" && lower != "0" {
+			warnings.Add(NewWarning("nagopher: threshold [%s] of metric [%s] without upper bound is not supported by checkmk",
+				bounds.ToNagiosRange(), metric.Name()))
+		}
+		return ""
+	}
+
+	if lower == "" || lower == "0" {
+		return upper
+	}
+
+	return lower + ":" + upper
+}
+
+func formatCheckmkBound(value float64, err error) string {
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return ""
+	}
+
+	return formatCheckmkValue(value)
+}
+
+func formatCheckmkValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, strconv.IntSize)
+}
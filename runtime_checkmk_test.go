@@ -0,0 +1,124 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type mockCheckmkResource struct {
+	Resource
+}
+
+func TestCheckmkRuntime_Execute(t *testing.T) {
+	// given
+	check := newMockDaemonCheck()
+
+	// when
+	result := NewCheckmkRuntime().Execute(check)
+
+	// then
+	assert.Equal(t, StateWarning().ExitCode(), result.ExitCode())
+	assert.Equal(t, `1 "usage" usage1=49.4;10:80|usage2=92.6;10:80 usage2 is 92.6% (outside range 10:80)`+"\n",
+		result.Output())
+}
+
+func TestCheckmkRuntime_ExecuteAll(t *testing.T) {
+	// given
+	warningThreshold := NewBounds(LowerBound(0), UpperBound(2))
+	criticalThreshold := NewBounds(LowerBound(5), UpperBound(10), InvertedBounds(true))
+	check1 := NewCheck(`cpu "load" avg`, NewSummarizer())
+	check1.AttachResources(&mockCheckmkResource{NewResource()})
+	check1.AttachContexts(NewScalarContext("load", &warningThreshold, &criticalThreshold))
+	check2 := NewCheck("", NewSummarizer())
+	check2.AttachResources(newMockProbeErrorResource())
+
+	// when
+	result := NewCheckmkRuntime(CheckmkDynamicState(true), CheckmkVerboseOutput(true)).ExecuteAll(check1, check2)
+
+	// then
+	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
+	assert.Equal(t, `P "cpu 'load' avg" load_1=1.5;2;;0;100 load 1 is 1.5\n`+
+		`nagopher: inverted threshold [@5:10] of metric [load 1] is not supported by checkmk`+"\n"+
+		`3 "nagopher" - probe: artificial error happened here\nunknown: probe: artificial error happened here`+"\n", result.Output())
+}
+
+func TestCheckmkRuntime_ExecuteAll_Panic(t *testing.T) {
+	// given
+	check := NewCheck("usage", &mockPanicSummarizer{NewSummarizer()})
+	check.AttachResources(newMockDaemonResource())
+	check.AttachContexts(NewScalarContext("usage", nil, nil))
+
+	// when
+	result := NewCheckmkRuntime().ExecuteAll(check, newMockDaemonCheck())
+
+	// then
+	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
+	assert.Equal(t, `3 "usage" - nagopher: check panicked with [artificial panic happened here]`+"\n"+
+		`1 "usage" usage1=49.4;10:80|usage2=92.6;10:80 usage2 is 92.6% (outside range 10:80)`+"\n", result.Output())
+}
+
+func TestCheckmkRuntime_ExecuteAndExit(t *testing.T) {
+	var resultExitCode = -1
+	var resultOutput = ""
+
+	// given
+	resultExitFunction = func(exitCode int) { resultExitCode = exitCode }
+	resultOutputFunction = func(values ...interface{}) (int, error) {
+		resultOutput = fmt.Sprint(values...)
+		return len(resultOutput), nil
+	}
+
+	// when
+	NewCheckmkRuntime().ExecuteAndExit(newMockDaemonCheck())
+
+	// then
+	assert.Equal(t, 0, resultExitCode)
+	assert.Equal(t, `1 "usage" usage1=49.4;10:80|usage2=92.6;10:80 usage2 is 92.6% (outside range 10:80)`+"\n",
+		resultOutput)
+}
+
+func TestFormatCheckmkThreshold(t *testing.T) {
+	// given
+	metric := MustNewNumericMetric("test", 1, "", nil, "")
+	warnings := NewWarningCollection()
+
+	// when
+	threshold1 := formatCheckmkThreshold(NewOptionalBounds(NewBounds(LowerBound(10), UpperBound(20))), metric, warnings)
+	threshold2 := formatCheckmkThreshold(NewOptionalBounds(NewBounds(UpperBound(20))), metric, warnings)
+	threshold3 := formatCheckmkThreshold(NewOptionalBounds(NewBounds(LowerBound(10))), metric, warnings)
+	threshold4 := formatCheckmkThreshold(OptionalBounds{}, metric, warnings)
+
+	// then
+	assert.Equal(t, "10:20", threshold1)
+	assert.Equal(t, "20", threshold2)
+	assert.Equal(t, "", threshold3)
+	assert.Equal(t, "", threshold4)
+	assert.Equal(t, 1, len(warnings.GetWarningStrings()))
+}
+
+func (r mockCheckmkResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	valueRange := NewBounds(LowerBound(0), UpperBound(100))
+
+	return []Metric{
+		MustNewNumericMetric("load 1", 1.5, "", &valueRange, "load"),
+	}, nil
+}
@@ -53,6 +53,18 @@ type mockPerformanceErrorResource struct {
 	Resource
 }
 
+type mockPanicResource struct {
+	Resource
+}
+
+type mockPanicContext struct {
+	Context
+}
+
+type mockPanicSummarizer struct {
+	Summarizer
+}
+
 func TestBaseRuntime_Execute(t *testing.T) {
 	// given
 	warningThreshold := NewBounds(LowerBound(10), UpperBound(80))
@@ -119,7 +131,7 @@ func TestBaseRuntime_Execute_MissingContext(t *testing.T) {
 	// then
 	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
 	assert.Equal(t, strings.Join([]string{
-		"CHECK UNKNOWN - nagopher: missing context with name [usage]",
+		"CHECK UNKNOWN - evaluate: nagopher: missing context with name [usage] for metric [usage1]",
 	}, "\n")+"\n", result.Output())
 }
 
@@ -134,7 +146,7 @@ func TestBaseRuntime_Execute_Empty(t *testing.T) {
 	// then
 	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
 	assert.Equal(t, strings.Join([]string{
-		"CHECK UNKNOWN - nagopher: resource [*nagopher.mockEmptyResource] did not return any metrics",
+		"CHECK UNKNOWN - probe: nagopher: resource [*nagopher.mockEmptyResource] did not return any metrics",
 	}, "\n")+"\n", result.Output())
 }
 
@@ -149,7 +161,7 @@ func TestBaseRuntime_Execute_ProbeError(t *testing.T) {
 	// then
 	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
 	assert.Equal(t, strings.Join([]string{
-		"CHECK UNKNOWN - artificial error happened here",
+		"CHECK UNKNOWN - probe: artificial error happened here",
 	}, "\n")+"\n", result.Output())
 }
 
@@ -164,7 +176,7 @@ func TestBaseRuntime_Execute_SetupError(t *testing.T) {
 	// then
 	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
 	assert.Equal(t, strings.Join([]string{
-		"CHECK UNKNOWN - artificial error happened here",
+		"CHECK UNKNOWN - setup: artificial error happened here",
 	}, "\n")+"\n", result.Output())
 }
 
@@ -180,7 +192,7 @@ func TestBaseRuntime_Execute_TeardownError(t *testing.T) {
 	// then
 	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
 	assert.Equal(t, strings.Join([]string{
-		"CHECK UNKNOWN - artificial error happened here",
+		"CHECK UNKNOWN - teardown: artificial error happened here",
 	}, "\n")+"\n", result.Output())
 }
 
@@ -196,10 +208,70 @@ func TestBaseRuntime_Execute_PerformanceError(t *testing.T) {
 	// then
 	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
 	assert.Equal(t, strings.Join([]string{
-		"CHECK UNKNOWN - nagopher: collecting performance data failed with [perfdata metric name [inv'=alid] contains invalid characters]",
+		"CHECK UNKNOWN - evaluate: nagopher: collecting performance data failed with [perfdata metric name [inv'=alid] contains invalid characters]",
 	}, "\n")+"\n", result.Output())
 }
 
+func TestBaseRuntime_Execute_ResourcePanic(t *testing.T) {
+	// given
+	check := NewCheck("check", NewSummarizer())
+	check.AttachResources(newMockPanicResource(), newMockDaemonResource())
+	check.AttachContexts(NewScalarContext("usage", nil, nil))
+
+	// when
+	result1 := NewRuntime(true).Execute(check)
+	result2 := NewRuntime(false, RuntimeVerbosity(3)).Execute(check)
+
+	// then
+	assert.Equal(t, StateUnknown().ExitCode(), result1.ExitCode())
+	assert.Equal(t, strings.Join([]string{
+		"CHECK UNKNOWN - probe: nagopher: resource panicked with [artificial panic happened here]",
+		"unknown: probe: nagopher: resource panicked with [artificial panic happened here]",
+	}, "\n")+"\n", result1.Output())
+	assert.Equal(t, 3, check.Results().Count())
+	assert.Equal(t, 2, len(check.PerfData()))
+
+	assert.True(t, strings.HasPrefix(result2.Output(), result1.Output()+
+		"resource [*nagopher.mockPanicResource] during probe panicked with [artificial panic happened here]\ngoroutine "))
+	assert.Contains(t, result2.Output(), "mockPanicResource.Probe")
+}
+
+func TestBaseRuntime_Execute_ContextPanic(t *testing.T) {
+	// given
+	check := NewCheck("check", NewSummarizer())
+	check.AttachResources(newMockDaemonResource())
+	check.AttachContexts(&mockPanicContext{NewScalarContext("usage", nil, nil)})
+
+	// when
+	result := NewRuntime(true).Execute(check)
+
+	// then
+	assert.Equal(t, StateUnknown().ExitCode(), result.ExitCode())
+	assert.Equal(t, strings.Join([]string{
+		"CHECK UNKNOWN - 49.4% (nagopher: context [usage] panicked with [artificial panic happened here])",
+		"unknown: 49.4% (nagopher: context [usage] panicked with [artificial panic happened here])",
+		"unknown: 92.6% (nagopher: context [usage] panicked with [artificial panic happened here])",
+	}, "\n")+"\n", result.Output())
+	assert.Equal(t, 2, len(check.(DiagnosticCheck).PanicTraces()))
+}
+
+func TestBaseRuntime_Execute_CheckPanic(t *testing.T) {
+	// given
+	check := NewCheck("check", &mockPanicSummarizer{NewSummarizer()})
+	check.AttachResources(newMockDaemonResource())
+	check.AttachContexts(NewScalarContext("usage", nil, nil))
+
+	// when
+	result1 := NewRuntime(true).Execute(check)
+	result2 := NewRuntime(false, RuntimeVerbosity(3)).Execute(check)
+
+	// then
+	assert.Equal(t, StateUnknown().ExitCode(), result1.ExitCode())
+	assert.Equal(t, "CHECK UNKNOWN - nagopher: check panicked with [artificial panic happened here]\n", result1.Output())
+	assert.True(t, strings.HasPrefix(result2.Output(), result1.Output()+
+		"check panicked with [artificial panic happened here]\ngoroutine "))
+}
+
 func TestBaseRuntime_ExecuteAndExit(t *testing.T) {
 	var resultExitCode = -1
 	var resultOutput = ""
@@ -318,3 +390,21 @@ func (r mockPerformanceErrorResource) Probe(warnings WarningCollection) ([]Metri
 		MustNewNumericMetric("inv'=alid", 49.4, "%", nil, "usage"),
 	}, nil
 }
+
+func newMockPanicResource() Resource {
+	return &mockPanicResource{
+		Resource: NewResource(),
+	}
+}
+
+func (r mockPanicResource) Probe(warnings WarningCollection) ([]Metric, error) {
+	panic("artificial panic happened here")
+}
+
+func (c mockPanicContext) Evaluate(metric Metric, resource Resource) Result {
+	panic("artificial panic happened here")
+}
+
+func (s mockPanicSummarizer) Ok(check Check) string {
+	panic("artificial panic happened here")
+}
@@ -0,0 +1,277 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleWindow represents a recurring period of time, e.g. every weekday during the night
+type ScheduleWindow interface {
+	Contains(t time.Time) bool
+}
+
+type weeklyWindow struct {
+	weekdays map[time.Weekday]struct{}
+	start    time.Duration
+	end      time.Duration
+	location *time.Location
+}
+
+type cronWindow struct {
+	minutes  cronField
+	hours    cronField
+	days     cronField
+	months   cronField
+	weekdays cronField
+	location *time.Location
+}
+
+type cronField struct {
+	values     map[int]struct{}
+	restricted bool
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// NewWeeklyWindow instantiates a new ScheduleWindow, which is active on the given weekdays between start and end, both
+// being specified as offset since midnight. Windows with an end before their start span across midnight and belong to
+// the weekday they start on. An empty list of weekdays matches all days. A nil location defaults to time.Local.
+func NewWeeklyWindow(weekdays []time.Weekday, start time.Duration, end time.Duration, location *time.Location) ScheduleWindow {
+	if location == nil {
+		location = time.Local
+	}
+
+	weeklyWindow := &weeklyWindow{
+		weekdays: make(map[time.Weekday]struct{}),
+		start:    start,
+		end:      end,
+		location: location,
+	}
+
+	for _, weekday := range weekdays {
+		weeklyWindow.weekdays[weekday] = struct{}{}
+	}
+	if len(weekdays) == 0 {
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			weeklyWindow.weekdays[weekday] = struct{}{}
+		}
+	}
+
+	return weeklyWindow
+}
+
+// ParseWeeklyWindow is a helper method, which constructs a new weekly ScheduleWindow from a specifier consisting of an
+// optional list of weekdays and an optional time range, e.g. "Mon-Fri 22:00-06:00", "Sat,Sun" or "01:00-05:00".
+func ParseWeeklyWindow(specifier string, location *time.Location) (ScheduleWindow, error) {
+	var weekdays []time.Weekday
+	start, end := time.Duration(0), 24*time.Hour
+
+	for _, part := range strings.Fields(specifier) {
+		if strings.Contains(part, ":") {
+			times := strings.Split(part, "-")
+			if len(times) != 2 {
+				return nil, fmt.Errorf("time range [%s] must be in the format HH:MM-HH:MM", part)
+			}
+
+			var err error
+			if start, err = parseTimeOfDay(times[0]); err != nil {
+				return nil, err
+			}
+			if end, err = parseTimeOfDay(times[1]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		field, err := parseCronField(part, 0, 7, weekdayNames)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse weekdays [%s] (%s)", part, err.Error())
+		}
+		for value := range field.values {
+			weekdays = append(weekdays, time.Weekday(value%7))
+		}
+	}
+
+	return NewWeeklyWindow(weekdays, start, end, location), nil
+}
+
+// ParseCronWindow is a helper method, which constructs a new ScheduleWindow from a cron-like specifier consisting of
+// the five fields minute, hour, day of month, month and day of week. The window contains every minute matching all
+// fields, e.g. "* 0-5 * * mon-fri" contains all weekday nights until 6am. Fields support lists, ranges, steps and
+// names for months and weekdays. A nil location defaults to time.Local.
+func ParseCronWindow(specifier string, location *time.Location) (ScheduleWindow, error) {
+	fields := strings.Fields(specifier)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron specifier [%s] must consist of exactly five fields", specifier)
+	}
+
+	if location == nil {
+		location = time.Local
+	}
+
+	var err error
+	cronWindow := &cronWindow{location: location}
+	if cronWindow.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("could not parse cron minutes [%s] (%s)", fields[0], err.Error())
+	}
+	if cronWindow.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("could not parse cron hours [%s] (%s)", fields[1], err.Error())
+	}
+	if cronWindow.days, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("could not parse cron days [%s] (%s)", fields[2], err.Error())
+	}
+	if cronWindow.months, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("could not parse cron months [%s] (%s)", fields[3], err.Error())
+	}
+	if cronWindow.weekdays, err = parseCronField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("could not parse cron weekdays [%s] (%s)", fields[4], err.Error())
+	}
+
+	if _, ok := cronWindow.weekdays.values[7]; ok {
+		cronWindow.weekdays.values[0] = struct{}{}
+	}
+
+	return cronWindow, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("time of day [%s] must be in the format HH:MM", value)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("time of day [%s] contains invalid hours", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("time of day [%s] contains invalid minutes", value)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+func parseCronField(specifier string, min int, max int, names map[string]int) (cronField, error) {
+	field := cronField{values: make(map[int]struct{})}
+
+	for _, part := range strings.Split(specifier, ",") {
+		step := 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[index+1:]); err != nil || step <= 0 {
+				return cronField{}, fmt.Errorf("invalid step [%s]", part[index+1:])
+			}
+			part = part[:index]
+		}
+
+		low, high := min, max
+		if part != "*" {
+			field.restricted = true
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			if low, err = parseCronValue(bounds[0], min, max, names); err != nil {
+				return cronField{}, err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = parseCronValue(bounds[1], min, max, names); err != nil {
+					return cronField{}, err
+				}
+			} else if step != 1 {
+				high = max
+			}
+		}
+
+		if low > high {
+			return cronField{}, fmt.Errorf("invalid range [%s]", part)
+		}
+		for value := low; value <= high; value += step {
+			field.values[value] = struct{}{}
+		}
+	}
+
+	return field, nil
+}
+
+func parseCronValue(value string, min int, max int, names map[string]int) (int, error) {
+	if named, ok := names[strings.ToLower(value)]; ok {
+		return named, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value [%s]", value)
+	}
+	if number < min || number > max {
+		return 0, fmt.Errorf("value [%s] out of range %d-%d", value, min, max)
+	}
+
+	return number, nil
+}
+
+func (w weeklyWindow) Contains(t time.Time) bool {
+	t = t.In(w.location)
+	// The offset is based on the wall clock, as subtracting midnight would be off by the DST shift on transition days
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+
+	if w.start <= w.end {
+		return w.hasWeekday(t.Weekday()) && offset >= w.start && offset < w.end
+	}
+
+	previousDay := (t.Weekday() + 6) % 7
+	return (w.hasWeekday(t.Weekday()) && offset >= w.start) || (w.hasWeekday(previousDay) && offset < w.end)
+}
+
+func (w weeklyWindow) hasWeekday(weekday time.Weekday) bool {
+	_, ok := w.weekdays[weekday]
+	return ok
+}
+
+func (w cronWindow) Contains(t time.Time) bool {
+	t = t.In(w.location)
+	if !w.minutes.contains(t.Minute()) || !w.hours.contains(t.Hour()) || !w.months.contains(int(t.Month())) {
+		return false
+	}
+
+	// Standard cron semantics: if both day fields are restricted, either of them has to match
+	if w.days.restricted && w.weekdays.restricted {
+		return w.days.contains(t.Day()) || w.weekdays.contains(int(t.Weekday()))
+	}
+
+	return w.days.contains(t.Day()) && w.weekdays.contains(int(t.Weekday()))
+}
+
+func (f cronField) contains(value int) bool {
+	_, ok := f.values[value]
+	return ok
+}
@@ -0,0 +1,126 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewWeeklyWindow(t *testing.T) {
+	// given
+	window := NewWeeklyWindow([]time.Weekday{time.Friday}, 22*time.Hour, 6*time.Hour, time.UTC)
+
+	// then
+	assert.True(t, window.Contains(time.Date(2019, 6, 21, 23, 0, 0, 0, time.UTC)))  // Friday
+	assert.True(t, window.Contains(time.Date(2019, 6, 22, 5, 59, 0, 0, time.UTC)))  // Saturday morning
+	assert.False(t, window.Contains(time.Date(2019, 6, 22, 6, 0, 0, 0, time.UTC)))  // Saturday morning
+	assert.False(t, window.Contains(time.Date(2019, 6, 21, 5, 0, 0, 0, time.UTC)))  // Friday morning
+	assert.False(t, window.Contains(time.Date(2019, 6, 22, 23, 0, 0, 0, time.UTC))) // Saturday
+}
+
+func TestWeeklyWindow_Contains_DST(t *testing.T) {
+	// given
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+	window := NewWeeklyWindow([]time.Weekday{time.Sunday}, 2*time.Hour, 4*time.Hour, location)
+
+	// then
+	assert.True(t, window.Contains(time.Date(2019, 3, 31, 1, 30, 0, 0, time.UTC)))   // 03:30 CEST
+	assert.False(t, window.Contains(time.Date(2019, 3, 31, 2, 30, 0, 0, time.UTC)))  // 04:30 CEST
+	assert.True(t, window.Contains(time.Date(2019, 10, 27, 2, 30, 0, 0, time.UTC)))  // 03:30 CET
+	assert.False(t, window.Contains(time.Date(2019, 10, 27, 3, 30, 0, 0, time.UTC))) // 04:30 CET
+}
+
+func TestParseWeeklyWindow(t *testing.T) {
+	// given
+	location := time.FixedZone("UTC+2", 2*60*60)
+
+	// when
+	window1, err1 := ParseWeeklyWindow("Mon-Fri 08:00-18:00", location)
+	window2, err2 := ParseWeeklyWindow("sat,sun", location)
+	window3, err3 := ParseWeeklyWindow("01:00-05:00", location)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+
+	assert.True(t, window1.Contains(time.Date(2019, 6, 21, 6, 0, 0, 0, time.UTC)))   // Friday, 08:00 local
+	assert.False(t, window1.Contains(time.Date(2019, 6, 21, 5, 59, 0, 0, time.UTC))) // Friday, 07:59 local
+	assert.False(t, window1.Contains(time.Date(2019, 6, 22, 10, 0, 0, 0, time.UTC))) // Saturday
+	assert.True(t, window2.Contains(time.Date(2019, 6, 22, 10, 0, 0, 0, time.UTC)))  // Saturday
+	assert.True(t, window2.Contains(time.Date(2019, 6, 21, 23, 0, 0, 0, time.UTC)))  // Saturday, 01:00 local
+	assert.True(t, window3.Contains(time.Date(2019, 6, 19, 0, 30, 0, 0, time.UTC)))  // 02:30 local
+	assert.False(t, window3.Contains(time.Date(2019, 6, 19, 3, 0, 0, 0, time.UTC)))  // 05:00 local
+}
+
+func TestParseWeeklyWindow_Invalid(t *testing.T) {
+	// when
+	_, err1 := ParseWeeklyWindow("Mon-Fri 08:00", nil)
+	_, err2 := ParseWeeklyWindow("Mon-Fri 08:00-25:00", nil)
+	_, err3 := ParseWeeklyWindow("Someday", nil)
+	_, err4 := ParseWeeklyWindow("Fri-Mon", nil)
+
+	// then
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+	assert.Error(t, err4)
+}
+
+func TestParseCronWindow(t *testing.T) {
+	// when
+	window1, err1 := ParseCronWindow("* 0-5 * * mon-fri", time.UTC)
+	window2, err2 := ParseCronWindow("*/15 12 * dec *", time.UTC)
+	window3, err3 := ParseCronWindow("* * 1 * 7", time.UTC)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+
+	assert.True(t, window1.Contains(time.Date(2019, 6, 21, 5, 59, 0, 0, time.UTC)))  // Friday
+	assert.False(t, window1.Contains(time.Date(2019, 6, 21, 6, 0, 0, 0, time.UTC)))  // Friday
+	assert.False(t, window1.Contains(time.Date(2019, 6, 22, 3, 0, 0, 0, time.UTC)))  // Saturday
+	assert.True(t, window2.Contains(time.Date(2019, 12, 1, 12, 45, 0, 0, time.UTC))) // December
+	assert.False(t, window2.Contains(time.Date(2019, 12, 1, 12, 46, 0, 0, time.UTC)))
+	assert.True(t, window3.Contains(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)))   // 1st of month
+	assert.True(t, window3.Contains(time.Date(2019, 6, 23, 12, 0, 0, 0, time.UTC)))  // Sunday
+	assert.False(t, window3.Contains(time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC))) // Monday
+}
+
+func TestParseCronWindow_Invalid(t *testing.T) {
+	// when
+	_, err1 := ParseCronWindow("* * * *", nil)
+	_, err2 := ParseCronWindow("60 * * * *", nil)
+	_, err3 := ParseCronWindow("* */0 * * *", nil)
+	_, err4 := ParseCronWindow("* * * foo *", nil)
+	_, err5 := ParseCronWindow("* 5-1 * * *", nil)
+
+	// then
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+	assert.Error(t, err4)
+	assert.Error(t, err5)
+}
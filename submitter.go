@@ -0,0 +1,284 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PassiveCheckResult represents the result of a check, which should be submitted passively for the given host and
+// service. An empty service name refers to a host check result.
+type PassiveCheckResult interface {
+	CheckResult
+
+	Host() string
+	Service() string
+	Timestamp() time.Time
+}
+
+// PassiveSubmitter sends passive check results to a monitoring system, e.g. by using NSCA or the Nagios command file
+type PassiveSubmitter interface {
+	Submit(result PassiveCheckResult) error
+}
+
+// SpoolingSubmitter is a PassiveSubmitter, which retries failed submissions and spools results to disk in case the
+// target is still not available. Spooled results are sent again before the next submission or on Flush(), so that the
+// target always receives results in chronological order. Spooled results which can not be parsed or have been rejected
+// too often are moved aside as "*.failed" files, so that they do not block the remaining backlog.
+type SpoolingSubmitter interface {
+	PassiveSubmitter
+
+	Flush() error
+}
+
+// SpoolingSubmitterOpt is a type alias for functional options used by NewSpoolingSubmitter()
+type SpoolingSubmitterOpt func(*spoolingSubmitter)
+
+type passiveCheckResult struct {
+	checkResult
+	host      string
+	service   string
+	timestamp time.Time
+}
+
+type spoolingSubmitter struct {
+	target        PassiveSubmitter
+	directory     string
+	attempts      int
+	retryDelay    time.Duration
+	flushAttempts int
+	mutex         sync.Mutex
+	sequence      int
+}
+
+type spooledCheckResult struct {
+	Host      string    `json:"host"`
+	Service   string    `json:"service"`
+	ExitCode  int8      `json:"exit_code"`
+	Output    string    `json:"output"`
+	Timestamp time.Time `json:"timestamp"`
+	Failures  int       `json:"failures,omitempty"`
+}
+
+// NewPassiveCheckResult instantiates a new PassiveCheckResult for the given host and service
+func NewPassiveCheckResult(host string, service string, result CheckResult, timestamp time.Time) PassiveCheckResult {
+	passiveCheckResult := &passiveCheckResult{
+		checkResult: checkResult{exitCode: result.ExitCode(), output: result.Output()},
+		host:        host,
+		service:     service,
+		timestamp:   timestamp,
+	}
+
+	return passiveCheckResult
+}
+
+// NewSpoolingSubmitter instantiates a new SpoolingSubmitter, which wraps the given target and spools results into the
+// given directory when the target is not available
+func NewSpoolingSubmitter(target PassiveSubmitter, directory string, options ...SpoolingSubmitterOpt) SpoolingSubmitter {
+	submitter := &spoolingSubmitter{
+		target:        target,
+		directory:     directory,
+		attempts:      1,
+		flushAttempts: 10,
+	}
+
+	for _, option := range options {
+		option(submitter)
+	}
+
+	return submitter
+}
+
+// SubmitRetries is a functional option for NewSpoolingSubmitter(), which sets the amount of attempts and the delay
+// between them before a result gets spooled to disk
+func SubmitRetries(attempts int, delay time.Duration) SpoolingSubmitterOpt {
+	return func(s *spoolingSubmitter) {
+		s.attempts = attempts
+		s.retryDelay = delay
+	}
+}
+
+// SpoolFlushAttempts is a functional option for NewSpoolingSubmitter(), which sets the amount of times a spooled result
+// may be rejected by the target before it gets quarantined. Defaults to 10 attempts.
+func SpoolFlushAttempts(attempts int) SpoolingSubmitterOpt {
+	return func(s *spoolingSubmitter) {
+		s.flushAttempts = attempts
+	}
+}
+
+func (r passiveCheckResult) Host() string {
+	return r.host
+}
+
+func (r passiveCheckResult) Service() string {
+	return r.service
+}
+
+func (r passiveCheckResult) Timestamp() time.Time {
+	return r.timestamp
+}
+
+func (s *spoolingSubmitter) Submit(result PassiveCheckResult) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Spooled results are older than the given one and have to be submitted first, as they would otherwise overwrite the
+	// more recent state. While the backlog can not be flushed, the result gets queued behind it.
+	if err := s.flush(); err != nil {
+		if spoolErr := s.spool(result); spoolErr != nil {
+			return fmt.Errorf("nagopher: flushing spool failed with [%s] and spooling failed with [%s]", err.Error(), spoolErr.Error())
+		}
+
+		return nil
+	}
+
+	if err := s.submitWithRetries(result); err != nil {
+		if spoolErr := s.spool(result); spoolErr != nil {
+			return fmt.Errorf("nagopher: submission failed with [%s] and spooling failed with [%s]", err.Error(), spoolErr.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *spoolingSubmitter) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.flush()
+}
+
+func (s *spoolingSubmitter) submitWithRetries(result PassiveCheckResult) error {
+	var err error
+	for attempt := 0; attempt < s.attempts || attempt == 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.retryDelay)
+		}
+
+		if err = s.target.Submit(result); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func (s *spoolingSubmitter) spool(result PassiveCheckResult) error {
+	data, err := json.Marshal(spooledCheckResult{
+		Host:      result.Host(),
+		Service:   result.Service(),
+		ExitCode:  result.ExitCode(),
+		Output:    result.Output(),
+		Timestamp: result.Timestamp(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.directory, 0700); err != nil {
+		return err
+	}
+
+	s.sequence++
+	fileName := fmt.Sprintf("%020d-%06d.json", time.Now().UnixNano(), s.sequence)
+	return ioutil.WriteFile(filepath.Join(s.directory, fileName), data, 0600)
+}
+
+func (s *spoolingSubmitter) flush() error {
+	files, err := ioutil.ReadDir(s.directory)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	sort.Slice(files, func(a int, b int) bool {
+		return files[a].Name() < files[b].Name()
+	})
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		filePath := filepath.Join(s.directory, file.Name())
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		var spooledResult spooledCheckResult
+		if err := json.Unmarshal(data, &spooledResult); err != nil {
+			if err := quarantineSpoolFile(filePath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result := NewPassiveCheckResult(spooledResult.Host, spooledResult.Service,
+			NewCheckResult(spooledResult.ExitCode, spooledResult.Output), spooledResult.Timestamp)
+		if err := s.target.Submit(result); err != nil {
+			// The remaining backlog must wait for this result to keep the order, unless it has been rejected too often
+			spooledResult.Failures++
+			if spooledResult.Failures < s.flushAttempts {
+				if writeErr := s.rewriteSpoolFile(filePath, spooledResult); writeErr != nil {
+					return writeErr
+				}
+				return err
+			}
+
+			if err := quarantineSpoolFile(filePath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.Remove(filePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *spoolingSubmitter) rewriteSpoolFile(filePath string, spooledResult spooledCheckResult) error {
+	data, err := json.Marshal(spooledResult)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filePath, data, 0600)
+}
+
+func quarantineSpoolFile(filePath string) error {
+	return os.Rename(filePath, filePath+".failed")
+}
+
+func formatPassiveOutput(output string) string {
+	output = strings.TrimRight(output, "\n")
+	return strings.Replace(output, "\n", `\n`, -1)
+}
@@ -0,0 +1,68 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+type commandFileSubmitter struct {
+	path string
+}
+
+// NewCommandFileSubmitter instantiates a new PassiveSubmitter, which writes PROCESS_SERVICE_CHECK_RESULT respectively
+// PROCESS_HOST_CHECK_RESULT commands into the external command file (FIFO) of Nagios or Icinga. Submissions fail
+// immediately instead of blocking when the command file has no reader.
+func NewCommandFileSubmitter(path string) PassiveSubmitter {
+	return &commandFileSubmitter{
+		path: path,
+	}
+}
+
+func (s commandFileSubmitter) Submit(result PassiveCheckResult) error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return fmt.Errorf("nagopher: could not open command file (%s)", err.Error())
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(formatExternalCommand(result)); err != nil {
+		return fmt.Errorf("nagopher: could not write to command file (%s)", err.Error())
+	}
+
+	return nil
+}
+
+func formatExternalCommand(result PassiveCheckResult) string {
+	sanitize := func(value string) string {
+		return strings.Replace(strings.Replace(value, ";", "_", -1), "\n", " ", -1)
+	}
+
+	if result.Service() == "" {
+		return fmt.Sprintf("[%d] PROCESS_HOST_CHECK_RESULT;%s;%d;%s\n",
+			result.Timestamp().Unix(), sanitize(result.Host()), result.ExitCode(), formatPassiveOutput(result.Output()))
+	}
+
+	return fmt.Sprintf("[%d] PROCESS_SERVICE_CHECK_RESULT;%s;%s;%d;%s\n",
+		result.Timestamp().Unix(), sanitize(result.Host()), sanitize(result.Service()), result.ExitCode(),
+		formatPassiveOutput(result.Output()))
+}
@@ -0,0 +1,62 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCommandFileSubmitter_Submit(t *testing.T) {
+	// given
+	directory := createTempDirectory(t)
+	defer os.RemoveAll(directory)
+
+	commandFile := filepath.Join(directory, "nagios.cmd")
+	_ = ioutil.WriteFile(commandFile, []byte{}, 0600)
+	submitter := NewCommandFileSubmitter(commandFile)
+	timestamp := time.Unix(1561118400, 0)
+
+	// when
+	err1 := submitter.Submit(NewPassiveCheckResult("web;01", "http", NewCheckResult(1, "HTTP WARNING | time=1s\nslow\n"), timestamp))
+	err2 := submitter.Submit(NewPassiveCheckResult("web01", "", NewCheckResult(0, "PING OK\n"), timestamp))
+	content, _ := ioutil.ReadFile(commandFile)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, "[1561118400] PROCESS_SERVICE_CHECK_RESULT;web_01;http;1;HTTP WARNING | time=1s\\nslow\n"+
+		"[1561118400] PROCESS_HOST_CHECK_RESULT;web01;0;PING OK\n", string(content))
+}
+
+func TestCommandFileSubmitter_Submit_Missing(t *testing.T) {
+	// given
+	submitter := NewCommandFileSubmitter(filepath.Join(os.TempDir(), "nagopher-missing", "nagios.cmd"))
+
+	// when
+	err := submitter.Submit(NewPassiveCheckResult("host", "service", NewCheckResult(0, "OK"), time.Now()))
+
+	// then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not open command file")
+}
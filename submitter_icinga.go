@@ -0,0 +1,283 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// IcingaSubmitter is a PassiveSubmitter, which pushes check results into Icinga 2 by using the REST API action
+// "process-check-result". Next to plain results, it can also submit a Check directly, which transfers the performance
+// data and execution times as structured values.
+//
+// SubmitCheck executes the given check itself by using the runtime of the submitter, so that panics are recovered just
+// like for regular plugins. The check therefore must not have been executed before.
+type IcingaSubmitter interface {
+	PassiveSubmitter
+
+	SubmitCheck(host string, service string, check Check) error
+}
+
+// IcingaSubmitterOpt is a type alias for functional options used by NewIcingaSubmitter()
+type IcingaSubmitterOpt func(*icingaSubmitter)
+
+type icingaSubmitter struct {
+	baseURL     string
+	username    string
+	password    string
+	checkSource string
+	timeout     time.Duration
+	runtime     Runtime
+	tlsConfig   *tls.Config
+	client      *http.Client
+}
+
+type icingaCheckResult struct {
+	Type            string   `json:"type"`
+	Host            string   `json:"host,omitempty"`
+	Service         string   `json:"service,omitempty"`
+	ExitStatus      int8     `json:"exit_status"`
+	PluginOutput    string   `json:"plugin_output"`
+	PerformanceData []string `json:"performance_data,omitempty"`
+	CheckSource     string   `json:"check_source,omitempty"`
+	ExecutionStart  float64  `json:"execution_start,omitempty"`
+	ExecutionEnd    float64  `json:"execution_end,omitempty"`
+}
+
+type icingaResponse struct {
+	Results []struct {
+		Code   float64 `json:"code"`
+		Status string  `json:"status"`
+	} `json:"results"`
+}
+
+const icingaProcessCheckResultPath = "/v1/actions/process-check-result"
+
+// NewIcingaSubmitter instantiates a new IcingaSubmitter for the Icinga 2 API at the given base URL, e.g.
+// "https://icinga.example.com:5665". The check source defaults to the hostname of the local system.
+func NewIcingaSubmitter(baseURL string, options ...IcingaSubmitterOpt) IcingaSubmitter {
+	checkSource, _ := os.Hostname()
+	submitter := &icingaSubmitter{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		checkSource: checkSource,
+		timeout:     10 * time.Second,
+		runtime:     NewRuntime(false),
+		tlsConfig:   &tls.Config{},
+	}
+
+	for _, option := range options {
+		option(submitter)
+	}
+
+	// The client is shared by all submissions, so that connections to the API get reused instead of being leaked
+	submitter.client = &http.Client{
+		Timeout:   submitter.timeout,
+		Transport: &http.Transport{TLSClientConfig: submitter.tlsConfig},
+	}
+
+	return submitter
+}
+
+// IcingaBasicAuth is a functional option for NewIcingaSubmitter(), which authenticates the API user with a password
+func IcingaBasicAuth(username string, password string) IcingaSubmitterOpt {
+	return func(s *icingaSubmitter) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// IcingaClientCertificate is a functional option for NewIcingaSubmitter(), which authenticates the API user with a
+// client certificate. The common name of the certificate must match the "client_cn" attribute of the ApiUser object.
+func IcingaClientCertificate(certificate tls.Certificate) IcingaSubmitterOpt {
+	return func(s *icingaSubmitter) {
+		s.tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+}
+
+// IcingaCACertificates is a functional option for NewIcingaSubmitter(), which pins the certificate authorities being
+// trusted for the API endpoint, e.g. the CA of the Icinga 2 cluster, instead of using the system roots
+func IcingaCACertificates(pool *x509.CertPool) IcingaSubmitterOpt {
+	return func(s *icingaSubmitter) {
+		s.tlsConfig.RootCAs = pool
+	}
+}
+
+// IcingaCheckSource is a functional option for NewIcingaSubmitter(), which overrides the submitted check source
+func IcingaCheckSource(checkSource string) IcingaSubmitterOpt {
+	return func(s *icingaSubmitter) {
+		s.checkSource = checkSource
+	}
+}
+
+// IcingaTimeout is a functional option for NewIcingaSubmitter(), which limits the duration of a single submission
+func IcingaTimeout(timeout time.Duration) IcingaSubmitterOpt {
+	return func(s *icingaSubmitter) {
+		s.timeout = timeout
+	}
+}
+
+// IcingaRuntime is a functional option for NewIcingaSubmitter(), which sets the runtime used for executing checks passed
+// to SubmitCheck(). Defaults to a non-verbose runtime.
+func IcingaRuntime(runtime Runtime) IcingaSubmitterOpt {
+	return func(s *icingaSubmitter) {
+		s.runtime = runtime
+	}
+}
+
+func (s icingaSubmitter) Submit(result PassiveCheckResult) error {
+	payload := s.newResultPayload(result.Host(), result.Service(), result)
+	payload.ExecutionEnd = icingaTimestamp(result.Timestamp())
+
+	return s.send(payload)
+}
+
+func (s icingaSubmitter) SubmitCheck(host string, service string, check Check) error {
+	payload := s.newResultPayload(host, service, s.runtime.Execute(check))
+	executionStart, executionEnd := checkExecutionTimes(check)
+	payload.ExecutionStart = icingaTimestamp(executionStart)
+	payload.ExecutionEnd = icingaTimestamp(executionEnd)
+
+	return s.send(payload)
+}
+
+// newResultPayload splits the output of the given result into the plugin output and the performance data, as Icinga 2
+// expects the performance data to be passed separately
+func (s icingaSubmitter) newResultPayload(host string, service string, result CheckResult) icingaCheckResult {
+	output := strings.TrimRight(result.Output(), "\n")
+	statusLine, longOutput := output, ""
+	if index := strings.Index(output, "\n"); index >= 0 {
+		statusLine, longOutput = output[:index], output[index:]
+	}
+
+	var perfData []string
+	if index := strings.Index(statusLine, "|"); index >= 0 {
+		perfData = splitNagiosPerfData(statusLine[index+1:])
+		statusLine = strings.TrimSpace(statusLine[:index])
+	}
+
+	payload := s.newPayload(host, service)
+	payload.ExitStatus = result.ExitCode()
+	payload.PluginOutput = statusLine + longOutput
+	payload.PerformanceData = perfData
+
+	return payload
+}
+
+func (s icingaSubmitter) newPayload(host string, service string) icingaCheckResult {
+	if service == "" {
+		return icingaCheckResult{Type: "Host", Host: host, CheckSource: s.checkSource}
+	}
+
+	return icingaCheckResult{Type: "Service", Service: host + "!" + service, CheckSource: s.checkSource}
+}
+
+func (s icingaSubmitter) send(payload icingaCheckResult) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.baseURL+icingaProcessCheckResultPath, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("nagopher: could not create icinga request (%s)", err.Error())
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/json")
+	if s.username != "" {
+		request.SetBasicAuth(s.username, s.password)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("nagopher: could not submit check result to icinga (%s)", err.Error())
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("nagopher: could not read icinga response (%s)", err.Error())
+	}
+
+	var apiResponse icingaResponse
+	_ = json.Unmarshal(responseBody, &apiResponse)
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("nagopher: icinga returned status [%d] (%s)", response.StatusCode, strings.TrimSpace(string(responseBody)))
+	}
+	if len(apiResponse.Results) == 0 {
+		return fmt.Errorf("nagopher: icinga did not find a matching %s object", strings.ToLower(payload.Type))
+	}
+	for _, result := range apiResponse.Results {
+		if result.Code != http.StatusOK {
+			return fmt.Errorf("nagopher: icinga rejected check result with code [%.0f] (%s)", result.Code, result.Status)
+		}
+	}
+
+	return nil
+}
+
+func icingaTimestamp(timestamp time.Time) float64 {
+	if timestamp.IsZero() {
+		return 0
+	}
+
+	return float64(timestamp.UnixNano()) / float64(time.Second)
+}
+
+func splitNagiosPerfData(value string) []string {
+	var results []string
+	var current strings.Builder
+	quoted := false
+
+	for index := 0; index < len(value); index++ {
+		character := value[index]
+		switch {
+		case character == '\'':
+			if quoted && index+1 < len(value) && value[index+1] == '\'' {
+				current.WriteString("''")
+				index++
+				continue
+			}
+			quoted = !quoted
+			current.WriteByte(character)
+		case character == ' ' && !quoted:
+			if current.Len() > 0 {
+				results = append(results, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteByte(character)
+		}
+	}
+
+	if current.Len() > 0 {
+		results = append(results, current.String())
+	}
+
+	return results
+}
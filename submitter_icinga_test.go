@@ -0,0 +1,205 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIcingaSubmitter_SubmitCheck(t *testing.T) {
+	// given
+	defer mockCheckTimeFunction(time.Unix(1561118400, 0), 1500*time.Millisecond)()
+
+	var payload map[string]interface{}
+	server := httptest.NewTLSServer(mockIcingaHandler(t, &payload, http.StatusOK, 200))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	submitter := NewIcingaSubmitter(server.URL, IcingaBasicAuth("root", "secret"), IcingaCACertificates(pool),
+		IcingaCheckSource("agent01"))
+
+	// when
+	err := submitter.SubmitCheck("web01", "usage", newMockDaemonCheck())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "Service", payload["type"])
+	assert.Equal(t, "web01!usage", payload["service"])
+	assert.Equal(t, float64(1), payload["exit_status"])
+	assert.Equal(t, "USAGE WARNING - usage2 is 92.6% (outside range 10:80)", payload["plugin_output"])
+	assert.Equal(t, []interface{}{"usage1=49.4%;10:80", "usage2=92.6%;10:80"}, payload["performance_data"])
+	assert.Equal(t, "agent01", payload["check_source"])
+	assert.Equal(t, float64(1561118400), payload["execution_start"])
+	assert.Equal(t, float64(1561118401.5), payload["execution_end"])
+}
+
+func TestIcingaSubmitter_SubmitCheck_Runtime(t *testing.T) {
+	// given
+	var payload map[string]interface{}
+	server := httptest.NewServer(mockIcingaHandler(t, &payload, http.StatusOK, 200))
+	defer server.Close()
+
+	submitter := NewIcingaSubmitter(server.URL, IcingaRuntime(NewRuntime(true)))
+
+	// when
+	err := submitter.SubmitCheck("web01", "usage", newMockDaemonCheck())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), payload["exit_status"])
+	assert.Equal(t, "USAGE WARNING - usage2 is 92.6% (outside range 10:80)\nwarning: usage2 is 92.6% (outside range 10:80)",
+		payload["plugin_output"])
+}
+
+func TestIcingaSubmitter_SubmitCheck_Panic(t *testing.T) {
+	// given
+	var payload map[string]interface{}
+	server := httptest.NewServer(mockIcingaHandler(t, &payload, http.StatusOK, 200))
+	defer server.Close()
+
+	submitter := NewIcingaSubmitter(server.URL, IcingaRuntime(NewRuntime(true)))
+	check := NewCheck("usage", &mockPanicSummarizer{NewSummarizer()})
+	check.AttachResources(newMockDaemonResource())
+	check.AttachContexts(NewScalarContext("usage", nil, nil))
+
+	// when
+	err := submitter.SubmitCheck("web01", "usage", check)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), payload["exit_status"])
+	assert.Equal(t, "USAGE UNKNOWN - nagopher: check panicked with [artificial panic happened here]", payload["plugin_output"])
+	assert.NotContains(t, payload, "performance_data")
+}
+
+func TestIcingaSubmitter_Submit(t *testing.T) {
+	// given
+	var payload map[string]interface{}
+	server := httptest.NewServer(mockIcingaHandler(t, &payload, http.StatusOK, 200))
+	defer server.Close()
+
+	submitter := NewIcingaSubmitter(server.URL, IcingaBasicAuth("root", "secret"))
+	output := "PING OK - rta 1ms | rta=1ms;100;500 'packet loss'=0%\nreply from 10.0.0.1\n"
+
+	// when
+	err := submitter.Submit(NewPassiveCheckResult("web01", "", NewCheckResult(0, output), time.Unix(1561118400, 0)))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "Host", payload["type"])
+	assert.Equal(t, "web01", payload["host"])
+	assert.Equal(t, float64(0), payload["exit_status"])
+	assert.Equal(t, "PING OK - rta 1ms\nreply from 10.0.0.1", payload["plugin_output"])
+	assert.Equal(t, []interface{}{"rta=1ms;100;500", "'packet loss'=0%"}, payload["performance_data"])
+	assert.Equal(t, float64(1561118400), payload["execution_end"])
+	assert.NotContains(t, payload, "execution_start")
+}
+
+func TestIcingaSubmitter_ClientCertificate(t *testing.T) {
+	// given
+	var payload map[string]interface{}
+	server := httptest.NewUnstartedServer(mockIcingaHandler(t, &payload, http.StatusOK, 200))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	submitter1 := NewIcingaSubmitter(server.URL, IcingaCACertificates(pool),
+		IcingaClientCertificate(server.TLS.Certificates[0]))
+	submitter2 := NewIcingaSubmitter(server.URL, IcingaCACertificates(pool), IcingaTimeout(time.Second))
+	submitter3 := NewIcingaSubmitter(server.URL, IcingaClientCertificate(server.TLS.Certificates[0]))
+	result := NewPassiveCheckResult("web01", "http", NewCheckResult(0, "HTTP OK"), time.Now())
+
+	// when
+	err1 := submitter1.Submit(result)
+	err2 := submitter2.Submit(result)
+	err3 := submitter3.Submit(result)
+
+	// then
+	assert.NoError(t, err1)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+}
+
+func TestIcingaSubmitter_Errors(t *testing.T) {
+	// given
+	var payload map[string]interface{}
+	server1 := httptest.NewServer(mockIcingaHandler(t, &payload, http.StatusNotFound, 404))
+	server2 := httptest.NewServer(mockIcingaHandler(t, &payload, http.StatusOK, 500))
+	server3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	}))
+	defer server1.Close()
+	defer server2.Close()
+	defer server3.Close()
+	result := NewPassiveCheckResult("web01", "http", NewCheckResult(0, "HTTP OK"), time.Now())
+
+	// when
+	err1 := NewIcingaSubmitter(server1.URL).Submit(result)
+	err2 := NewIcingaSubmitter(server2.URL).Submit(result)
+	err3 := NewIcingaSubmitter(server3.URL).Submit(result)
+	err4 := NewIcingaSubmitter("http://127.0.0.1:0").Submit(result)
+
+	// then
+	assert.EqualError(t, err1, "nagopher: icinga returned status [404] (mock error)")
+	assert.EqualError(t, err2, "nagopher: icinga rejected check result with code [500] (mock status)")
+	assert.EqualError(t, err3, "nagopher: icinga did not find a matching service object")
+	assert.Error(t, err4)
+}
+
+func TestSplitNagiosPerfData(t *testing.T) {
+	assert.Equal(t, []string{"a=1", "'b c''s'=2;3", "d=4"}, splitNagiosPerfData(" a=1  'b c''s'=2;3 d=4 "))
+	assert.Nil(t, splitNagiosPerfData(" "))
+}
+
+func mockIcingaHandler(t *testing.T, payload *map[string]interface{}, statusCode int, resultCode int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, icingaProcessCheckResultPath, r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+
+		if username, password, ok := r.BasicAuth(); ok {
+			assert.Equal(t, "root", username)
+			assert.Equal(t, "secret", password)
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		*payload = nil
+		_ = json.Unmarshal(body, payload)
+
+		w.WriteHeader(statusCode)
+		if statusCode != http.StatusOK {
+			_, _ = w.Write([]byte("mock error\n"))
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"results":[{"code":%d,"status":"mock status"}]}`, resultCode)
+	})
+}
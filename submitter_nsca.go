@@ -0,0 +1,233 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"time"
+)
+
+// NSCAEncryption represents an encryption method supported by the NSCA protocol
+type NSCAEncryption int
+
+// NSCA encryption methods, which use the same identifiers as the "decryption_method" option of the NSCA daemon
+const (
+	NSCAEncryptionNone        NSCAEncryption = 0
+	NSCAEncryptionXOR         NSCAEncryption = 1
+	NSCAEncryptionDES         NSCAEncryption = 2
+	NSCAEncryption3DES        NSCAEncryption = 3
+	NSCAEncryptionRijndael128 NSCAEncryption = 14
+)
+
+const (
+	nscaPacketVersion        = 3
+	nscaInitPacketSize       = 132
+	nscaTransmittedIVSize    = 128
+	nscaMaxHostnameLength    = 64
+	nscaMaxDescriptionLength = 128
+	nscaDefaultOutputLength  = 512
+)
+
+// NSCASubmitterOpt is a type alias for functional options used by NewNSCASubmitter()
+type NSCASubmitterOpt func(*nscaSubmitter)
+
+type nscaSubmitter struct {
+	address         string
+	encryption      NSCAEncryption
+	password        string
+	timeout         time.Duration
+	maxOutputLength int
+}
+
+type cfb8Stream struct {
+	block    cipher.Block
+	register []byte
+	output   []byte
+}
+
+// NewNSCASubmitter instantiates a new PassiveSubmitter, which sends check results to the NSCA daemon at the given
+// address using the NSCA v3 protocol
+func NewNSCASubmitter(address string, options ...NSCASubmitterOpt) PassiveSubmitter {
+	submitter := &nscaSubmitter{
+		address:         address,
+		encryption:      NSCAEncryptionNone,
+		timeout:         10 * time.Second,
+		maxOutputLength: nscaDefaultOutputLength,
+	}
+
+	for _, option := range options {
+		option(submitter)
+	}
+
+	return submitter
+}
+
+// NSCAEncryptionMethod is a functional option for NewNSCASubmitter(), which sets the encryption method and password.
+// Both values have to match the configuration of the NSCA daemon.
+func NSCAEncryptionMethod(encryption NSCAEncryption, password string) NSCASubmitterOpt {
+	return func(s *nscaSubmitter) {
+		s.encryption = encryption
+		s.password = password
+	}
+}
+
+// NSCATimeout is a functional option for NewNSCASubmitter(), which limits the duration of a single submission. A timeout
+// of zero disables the limit.
+func NSCATimeout(timeout time.Duration) NSCASubmitterOpt {
+	return func(s *nscaSubmitter) {
+		s.timeout = timeout
+	}
+}
+
+// NSCAMaxOutputLength is a functional option for NewNSCASubmitter(), which sets the maximum plugin output length. It
+// must match the MAX_PLUGINOUTPUT_LENGTH the NSCA daemon was compiled with and defaults to 512.
+func NSCAMaxOutputLength(length int) NSCASubmitterOpt {
+	return func(s *nscaSubmitter) {
+		s.maxOutputLength = length
+	}
+}
+
+func (s nscaSubmitter) Submit(result PassiveCheckResult) error {
+	connection, err := net.DialTimeout("tcp", s.address, s.timeout)
+	if err != nil {
+		return fmt.Errorf("nagopher: could not connect to nsca daemon (%s)", err.Error())
+	}
+	defer connection.Close()
+	if s.timeout > 0 {
+		_ = connection.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	initPacket := make([]byte, nscaInitPacketSize)
+	if _, err := io.ReadFull(connection, initPacket); err != nil {
+		return fmt.Errorf("nagopher: could not read nsca init packet (%s)", err.Error())
+	}
+
+	iv := initPacket[:nscaTransmittedIVSize]
+	timestamp := binary.BigEndian.Uint32(initPacket[nscaTransmittedIVSize:])
+	packet := s.encodePacket(result, timestamp)
+
+	if err := s.encrypt(packet, iv); err != nil {
+		return err
+	}
+
+	if _, err := connection.Write(packet); err != nil {
+		return fmt.Errorf("nagopher: could not send nsca packet (%s)", err.Error())
+	}
+
+	return nil
+}
+
+func (s nscaSubmitter) packetSize() int {
+	size := 14 + nscaMaxHostnameLength + nscaMaxDescriptionLength + s.maxOutputLength
+	return (size + 3) / 4 * 4
+}
+
+func (s nscaSubmitter) encodePacket(result PassiveCheckResult, timestamp uint32) []byte {
+	packet := make([]byte, s.packetSize())
+	binary.BigEndian.PutUint16(packet[0:], nscaPacketVersion)
+	binary.BigEndian.PutUint32(packet[8:], timestamp)
+	binary.BigEndian.PutUint16(packet[12:], uint16(result.ExitCode()))
+
+	writeString := func(offset int, length int, value string) {
+		if len(value) > length-1 {
+			value = value[:length-1]
+		}
+		copy(packet[offset:offset+length], value)
+	}
+
+	offset := 14
+	writeString(offset, nscaMaxHostnameLength, result.Host())
+	offset += nscaMaxHostnameLength
+	writeString(offset, nscaMaxDescriptionLength, result.Service())
+	offset += nscaMaxDescriptionLength
+	writeString(offset, s.maxOutputLength, formatPassiveOutput(result.Output()))
+
+	binary.BigEndian.PutUint32(packet[4:], crc32.ChecksumIEEE(packet))
+	return packet
+}
+
+func (s nscaSubmitter) encrypt(packet []byte, iv []byte) error {
+	switch s.encryption {
+	case NSCAEncryptionNone:
+		return nil
+
+	case NSCAEncryptionXOR:
+		for index := range packet {
+			packet[index] ^= iv[index%len(iv)]
+			if len(s.password) > 0 {
+				packet[index] ^= s.password[index%len(s.password)]
+			}
+		}
+		return nil
+	}
+
+	var block cipher.Block
+	var err error
+
+	switch s.encryption {
+	case NSCAEncryptionDES:
+		block, err = des.NewCipher(nscaKey(s.password, 8))
+	case NSCAEncryption3DES:
+		block, err = des.NewTripleDESCipher(nscaKey(s.password, 24))
+	case NSCAEncryptionRijndael128:
+		block, err = aes.NewCipher(nscaKey(s.password, 32))
+	default:
+		return fmt.Errorf("nagopher: unsupported nsca encryption method [%d]", s.encryption)
+	}
+
+	if err != nil {
+		return fmt.Errorf("nagopher: could not initialize nsca encryption (%s)", err.Error())
+	}
+
+	newCFB8Stream(block, iv[:block.BlockSize()]).XORKeyStream(packet, packet)
+	return nil
+}
+
+func nscaKey(password string, size int) []byte {
+	key := make([]byte, size)
+	copy(key, password)
+
+	return key
+}
+
+// newCFB8Stream returns a cipher stream operating in 8-bit cipher feedback mode, as used by libmcrypt for "cfb"
+func newCFB8Stream(block cipher.Block, iv []byte) cipher.Stream {
+	return &cfb8Stream{
+		block:    block,
+		register: append([]byte{}, iv...),
+		output:   make([]byte, block.BlockSize()),
+	}
+}
+
+func (s *cfb8Stream) XORKeyStream(dst []byte, src []byte) {
+	for index := range src {
+		s.block.Encrypt(s.output, s.register)
+		dst[index] = src[index] ^ s.output[0]
+
+		copy(s.register, s.register[1:])
+		s.register[len(s.register)-1] = dst[index]
+	}
+}
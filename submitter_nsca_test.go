@@ -0,0 +1,196 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNSCASubmitter_Submit(t *testing.T) {
+	encryptions := []NSCAEncryption{
+		NSCAEncryptionNone, NSCAEncryptionXOR, NSCAEncryptionDES, NSCAEncryption3DES, NSCAEncryptionRijndael128,
+	}
+
+	for _, encryption := range encryptions {
+		// given
+		packets, address := startMockNSCAServer(t, encryption, "secret", 720)
+		submitter := NewNSCASubmitter(address, NSCAEncryptionMethod(encryption, "secret"), NSCATimeout(time.Second))
+		result := NewPassiveCheckResult("web01", "http", NewCheckResult(1, "HTTP WARNING\nslow\n"), time.Now())
+
+		// when
+		err := submitter.Submit(result)
+		packet := <-packets
+
+		// then
+		assert.NoError(t, err, "encryption %d", encryption)
+		assert.Equal(t, 720, len(packet), "encryption %d", encryption)
+		assert.Equal(t, uint16(3), binary.BigEndian.Uint16(packet[0:]), "encryption %d", encryption)
+		assert.Equal(t, uint32(1561118400), binary.BigEndian.Uint32(packet[8:]), "encryption %d", encryption)
+		assert.Equal(t, uint16(1), binary.BigEndian.Uint16(packet[12:]), "encryption %d", encryption)
+		assert.Equal(t, "web01", nscaString(packet[14:78]), "encryption %d", encryption)
+		assert.Equal(t, "http", nscaString(packet[78:206]), "encryption %d", encryption)
+		assert.Equal(t, `HTTP WARNING\nslow`, nscaString(packet[206:718]), "encryption %d", encryption)
+	}
+}
+
+func TestNSCASubmitter_Submit_NoTimeout(t *testing.T) {
+	// given
+	packets, address := startMockNSCAServer(t, NSCAEncryptionNone, "", 720)
+	submitter := NewNSCASubmitter(address, NSCATimeout(0))
+	result := NewPassiveCheckResult("web01", "http", NewCheckResult(0, "HTTP OK"), time.Now())
+
+	// when
+	err := submitter.Submit(result)
+	packet := <-packets
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 720, len(packet))
+}
+
+func TestNSCASubmitter_Submit_MaxOutputLength(t *testing.T) {
+	// given
+	packets, address := startMockNSCAServer(t, NSCAEncryptionNone, "", 4304)
+	submitter := NewNSCASubmitter(address, NSCAMaxOutputLength(4096))
+	result := NewPassiveCheckResult("web01", "http", NewCheckResult(0, string(bytes.Repeat([]byte("x"), 5000))), time.Now())
+
+	// when
+	err := submitter.Submit(result)
+	packet := <-packets
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 4304, len(packet))
+	assert.Equal(t, 4095, len(nscaString(packet[206:4302])))
+}
+
+func TestNSCASubmitter_Submit_Errors(t *testing.T) {
+	// given
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	go func() {
+		connection, err := listener.Accept()
+		if err == nil {
+			_ = connection.Close()
+		}
+	}()
+
+	// when
+	err1 := NewNSCASubmitter(address, NSCATimeout(time.Second)).
+		Submit(NewPassiveCheckResult("host", "service", NewCheckResult(0, "OK"), time.Now()))
+	_ = listener.Close()
+	err2 := NewNSCASubmitter(address, NSCATimeout(time.Second)).
+		Submit(NewPassiveCheckResult("host", "service", NewCheckResult(0, "OK"), time.Now()))
+	err3 := nscaSubmitter{encryption: NSCAEncryption(42)}.encrypt(make([]byte, 16), make([]byte, 128))
+
+	// then
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+}
+
+func startMockNSCAServer(t *testing.T, encryption NSCAEncryption, password string, packetSize int) (chan []byte, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packets := make(chan []byte, 1)
+	go func() {
+		defer listener.Close()
+		connection, err := listener.Accept()
+		if err != nil {
+			packets <- nil
+			return
+		}
+		defer connection.Close()
+
+		initPacket := make([]byte, nscaInitPacketSize)
+		for index := 0; index < nscaTransmittedIVSize; index++ {
+			initPacket[index] = byte(index * 7)
+		}
+		binary.BigEndian.PutUint32(initPacket[nscaTransmittedIVSize:], 1561118400)
+		_, _ = connection.Write(initPacket)
+
+		packet := make([]byte, packetSize)
+		if _, err := io.ReadFull(connection, packet); err != nil {
+			packets <- nil
+			return
+		}
+
+		decryptNSCAPacket(packet, initPacket[:nscaTransmittedIVSize], encryption, password)
+		checksum := binary.BigEndian.Uint32(packet[4:])
+		binary.BigEndian.PutUint32(packet[4:], 0)
+		if crc32.ChecksumIEEE(packet) != checksum {
+			packets <- nil
+			return
+		}
+
+		packets <- packet
+	}()
+
+	return packets, listener.Addr().String()
+}
+
+func decryptNSCAPacket(packet []byte, iv []byte, encryption NSCAEncryption, password string) {
+	var block cipher.Block
+
+	switch encryption {
+	case NSCAEncryptionXOR:
+		_ = nscaSubmitter{encryption: encryption, password: password}.encrypt(packet, iv)
+		return
+	case NSCAEncryptionDES:
+		block, _ = des.NewCipher(nscaKey(password, 8))
+	case NSCAEncryption3DES:
+		block, _ = des.NewTripleDESCipher(nscaKey(password, 24))
+	case NSCAEncryptionRijndael128:
+		block, _ = aes.NewCipher(nscaKey(password, 32))
+	default:
+		return
+	}
+
+	register := append([]byte{}, iv[:block.BlockSize()]...)
+	output := make([]byte, block.BlockSize())
+	for index := range packet {
+		block.Encrypt(output, register)
+		cipherText := packet[index]
+		packet[index] ^= output[0]
+
+		copy(register, register[1:])
+		register[len(register)-1] = cipherText
+	}
+}
+
+func nscaString(data []byte) string {
+	if index := bytes.IndexByte(data, 0); index >= 0 {
+		return string(data[:index])
+	}
+
+	return string(data)
+}
@@ -0,0 +1,216 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type mockPassiveSubmitter struct {
+	failures  int
+	rejected  string
+	attempts  int
+	submitted []PassiveCheckResult
+}
+
+func (s *mockPassiveSubmitter) Submit(result PassiveCheckResult) error {
+	s.attempts++
+	if result.Service() == s.rejected {
+		return errors.New("unknown service")
+	}
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("target not available")
+	}
+
+	s.submitted = append(s.submitted, result)
+	return nil
+}
+
+func TestNewPassiveCheckResult(t *testing.T) {
+	// given
+	timestamp := time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC)
+
+	// when
+	result := NewPassiveCheckResult("host", "service", NewCheckResult(2, "CRITICAL"), timestamp)
+
+	// then
+	assert.Equal(t, "host", result.Host())
+	assert.Equal(t, "service", result.Service())
+	assert.Equal(t, int8(2), result.ExitCode())
+	assert.Equal(t, "CRITICAL", result.Output())
+	assert.Equal(t, timestamp, result.Timestamp())
+}
+
+func TestSpoolingSubmitter_Retries(t *testing.T) {
+	// given
+	target := &mockPassiveSubmitter{failures: 2}
+	submitter := NewSpoolingSubmitter(target, createTempDirectory(t), SubmitRetries(3, time.Millisecond))
+	result := NewPassiveCheckResult("host", "service", NewCheckResult(0, "OK"), time.Now())
+
+	// when
+	err := submitter.Submit(result)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 3, target.attempts)
+	assert.Equal(t, []PassiveCheckResult{result}, target.submitted)
+}
+
+func TestSpoolingSubmitter_Spool(t *testing.T) {
+	// given
+	directory := createTempDirectory(t)
+	defer os.RemoveAll(directory)
+
+	target := &mockPassiveSubmitter{failures: 2}
+	submitter := NewSpoolingSubmitter(target, directory)
+	timestamp := time.Date(2019, 6, 21, 12, 0, 0, 0, time.UTC)
+
+	// when
+	err1 := submitter.Submit(NewPassiveCheckResult("host", "first", NewCheckResult(1, "WARNING"), timestamp))
+	err2 := submitter.Submit(NewPassiveCheckResult("host", "second", NewCheckResult(2, "CRITICAL"), timestamp))
+	files, _ := ioutil.ReadDir(directory)
+	err3 := submitter.Submit(NewPassiveCheckResult("host", "third", NewCheckResult(0, "OK"), timestamp))
+	remainingFiles, _ := ioutil.ReadDir(directory)
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, 2, len(files))
+	assert.Equal(t, 0, len(remainingFiles))
+
+	assert.Equal(t, 3, len(target.submitted))
+	assert.Equal(t, "first", target.submitted[0].Service())
+	assert.Equal(t, int8(1), target.submitted[0].ExitCode())
+	assert.Equal(t, "WARNING", target.submitted[0].Output())
+	assert.True(t, timestamp.Equal(target.submitted[0].Timestamp()))
+	assert.Equal(t, "second", target.submitted[1].Service())
+	assert.Equal(t, "third", target.submitted[2].Service())
+}
+
+func TestSpoolingSubmitter_SpoolBehindBacklog(t *testing.T) {
+	// given
+	directory := createTempDirectory(t)
+	defer os.RemoveAll(directory)
+
+	target := &mockPassiveSubmitter{failures: 1}
+	submitter := NewSpoolingSubmitter(target, directory)
+	_ = submitter.Submit(NewPassiveCheckResult("host", "service", NewCheckResult(2, "CRITICAL"), time.Now()))
+	target.failures = 1
+
+	// when
+	err1 := submitter.Submit(NewPassiveCheckResult("host", "service", NewCheckResult(1, "WARNING"), time.Now()))
+	files, _ := ioutil.ReadDir(directory)
+	err2 := submitter.Submit(NewPassiveCheckResult("host", "service", NewCheckResult(0, "OK"), time.Now()))
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, 2, len(files))
+	assert.Equal(t, 3, len(target.submitted))
+	assert.Equal(t, "CRITICAL", target.submitted[0].Output())
+	assert.Equal(t, "WARNING", target.submitted[1].Output())
+	assert.Equal(t, "OK", target.submitted[2].Output())
+}
+
+func TestSpoolingSubmitter_Quarantine(t *testing.T) {
+	// given
+	directory := createTempDirectory(t)
+	defer os.RemoveAll(directory)
+
+	target := &mockPassiveSubmitter{rejected: "unknown"}
+	submitter := NewSpoolingSubmitter(target, directory, SpoolFlushAttempts(2))
+	_ = ioutil.WriteFile(filepath.Join(directory, "00000000000000000000-000000.json"), []byte("{invalid"), 0600)
+	_ = submitter.Submit(NewPassiveCheckResult("host", "unknown", NewCheckResult(0, "OK"), time.Now()))
+
+	// when
+	err1 := submitter.Submit(NewPassiveCheckResult("host", "first", NewCheckResult(0, "OK"), time.Now()))
+	submitted := len(target.submitted)
+	err2 := submitter.Submit(NewPassiveCheckResult("host", "second", NewCheckResult(0, "OK"), time.Now()))
+	quarantinedFiles, _ := filepath.Glob(filepath.Join(directory, "*.failed"))
+	remainingFiles, _ := filepath.Glob(filepath.Join(directory, "*.json"))
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, 0, submitted)
+	assert.Equal(t, 2, len(quarantinedFiles))
+	assert.Equal(t, 0, len(remainingFiles))
+	assert.Equal(t, 2, len(target.submitted))
+	assert.Equal(t, "first", target.submitted[0].Service())
+	assert.Equal(t, "second", target.submitted[1].Service())
+}
+
+func TestSpoolingSubmitter_Flush(t *testing.T) {
+	// given
+	directory := createTempDirectory(t)
+	defer os.RemoveAll(directory)
+
+	target := &mockPassiveSubmitter{failures: 1}
+	submitter := NewSpoolingSubmitter(target, directory)
+	_ = submitter.Submit(NewPassiveCheckResult("host", "service", NewCheckResult(0, "OK"), time.Now()))
+
+	// when
+	err1 := NewSpoolingSubmitter(&mockPassiveSubmitter{failures: 1}, directory).Flush()
+	err2 := submitter.Flush()
+	err3 := NewSpoolingSubmitter(target, filepath.Join(directory, "missing")).Flush()
+
+	// then
+	assert.Error(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, 1, len(target.submitted))
+}
+
+func TestSpoolingSubmitter_SpoolError(t *testing.T) {
+	// given
+	directory := createTempDirectory(t)
+	defer os.RemoveAll(directory)
+
+	blockingFile := filepath.Join(directory, "file")
+	_ = ioutil.WriteFile(blockingFile, []byte{}, 0600)
+	submitter := NewSpoolingSubmitter(&mockPassiveSubmitter{failures: 1}, blockingFile)
+
+	// when
+	err := submitter.Submit(NewPassiveCheckResult("host", "service", NewCheckResult(0, "OK"), time.Now()))
+
+	// then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spooling failed")
+}
+
+func TestFormatPassiveOutput(t *testing.T) {
+	assert.Equal(t, `USAGE OK | usage=1\nverbose line`, formatPassiveOutput("USAGE OK | usage=1\nverbose line\n"))
+}
+
+func createTempDirectory(t *testing.T) string {
+	directory, err := ioutil.TempDir("", "nagopher-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return directory
+}
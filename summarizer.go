@@ -61,19 +61,39 @@ func (s baseSummarizer) Verbose(check Check) []string {
 	for _, result := range check.Results().Get() {
 		state, err := result.State().Get()
 		if err != nil || state == StateInfo() {
-			messages = append(messages, fmt.Sprintf("info: %s", result))
+			messages = append(messages, fmt.Sprintf("info: %s", s.describeResult(result)))
 			continue
 		}
 
 		if state == StateOk() {
 			continue
 		}
-		messages = append(messages, fmt.Sprintf("%s: %s", state.Description(), result))
+		messages = append(messages, fmt.Sprintf("%s: %s", state.Description(), s.describeResult(result)))
 	}
 
 	return messages
 }
 
+func (s baseSummarizer) describeResult(result Result) string {
+	description := result.String()
+	metric, err := result.Metric().Get()
+	if err != nil {
+		return description
+	}
+
+	if transformedMetric, ok := metric.(TransformedMetric); ok {
+		description = fmt.Sprintf("%s (original value [%s])", description, transformedMetric.OriginalMetric().ToNagiosValue())
+	}
+
+	if resource, err := result.Resource().Get(); err == nil {
+		if name, ok := resourceName(resource); ok {
+			description = fmt.Sprintf("%s (resource [%s])", description, name)
+		}
+	}
+
+	return description
+}
+
 func (s baseSummarizer) Empty() string {
 	return "No check results"
 }
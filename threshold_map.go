@@ -0,0 +1,221 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ThresholdMap resolves warning and critical thresholds individually for each metric by evaluating an ordered list of
+// rules, e.g. matching the exact metric name, a glob or regular expression or a set of labels. The first matching rule
+// wins, falling back to an optional default when no rule matches.
+type ThresholdMap interface {
+	Resolve(metric Metric) (warningThreshold OptionalBounds, criticalThreshold OptionalBounds, ok bool)
+}
+
+// ThresholdMapOpt is a type alias for functional options used by NewThresholdMap()
+type ThresholdMapOpt func(*thresholdMap)
+
+type thresholdMap struct {
+	subjectLabel string
+	rules        []thresholdRule
+	defaultRule  *thresholdRule
+}
+
+type thresholdRule struct {
+	match             func(subject string, metric Metric) bool
+	warningThreshold  OptionalBounds
+	criticalThreshold OptionalBounds
+}
+
+// NewThresholdMap instantiates a new ThresholdMap with the given functional options. Rules are evaluated in the same
+// order as the options have been passed.
+func NewThresholdMap(options ...ThresholdMapOpt) ThresholdMap {
+	thresholdMap := &thresholdMap{}
+
+	for _, option := range options {
+		option(thresholdMap)
+	}
+
+	return thresholdMap
+}
+
+// ParseThresholdMap is a helper method, which constructs a new ThresholdMap from a specifier like "/var=80:90,/tmp=95:98".
+// Each comma-separated entry consists of a key and the warning/critical Nagios range specifiers separated by a colon.
+// Should either of them require a colon on their own, a semicolon can be used as separator instead, e.g. "db=5:10;0:20".
+// Keys starting with '~' are treated as regular expression, keys containing glob characters as glob and the key '*' is
+// used as default. All other keys must match exactly. The given options are applied before the parsed rules, which
+// allows using ThresholdSubjectLabel() to match keys against a label instead of the metric name.
+func ParseThresholdMap(specifier string, options ...ThresholdMapOpt) (ThresholdMap, error) {
+	for _, entry := range strings.Split(specifier, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		separatorIndex := strings.LastIndex(entry, "=")
+		if separatorIndex <= 0 {
+			return nil, fmt.Errorf("threshold map entry [%s] must be in the format key=warning:critical", entry)
+		}
+
+		key, thresholds := entry[:separatorIndex], entry[separatorIndex+1:]
+		warningThreshold, criticalThreshold, err := parseThresholdMapBounds(thresholds)
+		if err != nil {
+			return nil, fmt.Errorf("threshold map entry [%s] is invalid (%s)", entry, err.Error())
+		}
+
+		if key == "*" {
+			options = append(options, ThresholdDefault(warningThreshold, criticalThreshold))
+		} else if strings.HasPrefix(key, "~") {
+			pattern, err := regexp.Compile(key[1:])
+			if err != nil {
+				return nil, fmt.Errorf("threshold map entry [%s] contains invalid regular expression (%s)", entry, err.Error())
+			}
+			options = append(options, ThresholdRegexp(pattern, warningThreshold, criticalThreshold))
+		} else if strings.ContainsAny(key, "*?[") {
+			if _, err := path.Match(key, ""); err != nil {
+				return nil, fmt.Errorf("threshold map entry [%s] contains invalid glob pattern (%s)", entry, err.Error())
+			}
+			options = append(options, ThresholdGlob(key, warningThreshold, criticalThreshold))
+		} else {
+			options = append(options, ThresholdExact(key, warningThreshold, criticalThreshold))
+		}
+	}
+
+	return NewThresholdMap(options...), nil
+}
+
+func parseThresholdMapBounds(specifier string) (*Bounds, *Bounds, error) {
+	var parts []string
+	if strings.Contains(specifier, ";") {
+		parts = strings.Split(specifier, ";")
+	} else {
+		parts = strings.Split(specifier, ":")
+	}
+
+	if len(parts) > 2 {
+		return nil, nil, fmt.Errorf("expected at most two threshold ranges, got %d", len(parts))
+	}
+
+	var results []*Bounds
+	for _, part := range append(parts, "")[:2] {
+		if part == "" {
+			results = append(results, nil)
+			continue
+		}
+
+		bounds, err := NewBoundsFromNagiosRange(part)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = append(results, &bounds)
+	}
+
+	return results[0], results[1], nil
+}
+
+// ThresholdSubjectLabel is a functional option for NewThresholdMap(), which matches exact, glob and regular expression
+// rules against the value of the given label instead of the metric name.
+func ThresholdSubjectLabel(key string) ThresholdMapOpt {
+	return func(m *thresholdMap) {
+		m.subjectLabel = key
+	}
+}
+
+// ThresholdExact is a functional option for NewThresholdMap(), which adds a rule matching the exact subject
+func ThresholdExact(name string, warningThreshold *Bounds, criticalThreshold *Bounds) ThresholdMapOpt {
+	return newThresholdRuleOpt(func(subject string, metric Metric) bool {
+		return subject == name
+	}, warningThreshold, criticalThreshold)
+}
+
+// ThresholdGlob is a functional option for NewThresholdMap(), which adds a rule matching the subject against a glob
+// pattern as supported by path.Match()
+func ThresholdGlob(pattern string, warningThreshold *Bounds, criticalThreshold *Bounds) ThresholdMapOpt {
+	return newThresholdRuleOpt(func(subject string, metric Metric) bool {
+		match, err := path.Match(pattern, subject)
+		return err == nil && match
+	}, warningThreshold, criticalThreshold)
+}
+
+// ThresholdRegexp is a functional option for NewThresholdMap(), which adds a rule matching the subject against a
+// regular expression
+func ThresholdRegexp(pattern *regexp.Regexp, warningThreshold *Bounds, criticalThreshold *Bounds) ThresholdMapOpt {
+	return newThresholdRuleOpt(func(subject string, metric Metric) bool {
+		return pattern.MatchString(subject)
+	}, warningThreshold, criticalThreshold)
+}
+
+// ThresholdLabels is a functional option for NewThresholdMap(), which adds a rule matching all metrics carrying the
+// given labels
+func ThresholdLabels(selector Labels, warningThreshold *Bounds, criticalThreshold *Bounds) ThresholdMapOpt {
+	selector = selector.Copy()
+	return newThresholdRuleOpt(func(subject string, metric Metric) bool {
+		return metricLabels(metric).Matches(selector)
+	}, warningThreshold, criticalThreshold)
+}
+
+// ThresholdDefault is a functional option for NewThresholdMap(), which sets the thresholds being used in case no other
+// rule matches
+func ThresholdDefault(warningThreshold *Bounds, criticalThreshold *Bounds) ThresholdMapOpt {
+	return func(m *thresholdMap) {
+		rule := newThresholdRule(nil, warningThreshold, criticalThreshold)
+		m.defaultRule = &rule
+	}
+}
+
+func newThresholdRuleOpt(match func(string, Metric) bool, warningThreshold *Bounds, criticalThreshold *Bounds) ThresholdMapOpt {
+	return func(m *thresholdMap) {
+		m.rules = append(m.rules, newThresholdRule(match, warningThreshold, criticalThreshold))
+	}
+}
+
+func newThresholdRule(match func(string, Metric) bool, warningThreshold *Bounds, criticalThreshold *Bounds) thresholdRule {
+	rule := thresholdRule{match: match}
+
+	if warningThreshold != nil {
+		rule.warningThreshold = NewOptionalBounds(*warningThreshold)
+	}
+	if criticalThreshold != nil {
+		rule.criticalThreshold = NewOptionalBounds(*criticalThreshold)
+	}
+
+	return rule
+}
+
+func (m thresholdMap) Resolve(metric Metric) (OptionalBounds, OptionalBounds, bool) {
+	subject := metric.Name()
+	if m.subjectLabel != "" {
+		subject = metricLabels(metric)[m.subjectLabel]
+	}
+
+	for _, rule := range m.rules {
+		if rule.match(subject, metric) {
+			return rule.warningThreshold, rule.criticalThreshold, true
+		}
+	}
+
+	if m.defaultRule != nil {
+		return m.defaultRule.warningThreshold, m.defaultRule.criticalThreshold, true
+	}
+
+	return OptionalBounds{}, OptionalBounds{}, false
+}
@@ -0,0 +1,122 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestThresholdMap_Resolve(t *testing.T) {
+	// given
+	bounds1 := NewBounds(UpperBound(10))
+	bounds2 := NewBounds(UpperBound(20))
+	bounds3 := NewBounds(UpperBound(30))
+	bounds4 := NewBounds(UpperBound(40))
+	bounds5 := NewBounds(UpperBound(50))
+	thresholdMap := NewThresholdMap(
+		ThresholdExact("disk_var", &bounds1, nil),
+		ThresholdGlob("disk_*", &bounds2, nil),
+		ThresholdRegexp(regexp.MustCompile("^net_(eth|wlan)[0-9]$"), nil, &bounds3),
+		ThresholdLabels(Labels{"tier": "db"}, &bounds4, &bounds4),
+		ThresholdDefault(nil, &bounds5),
+	)
+
+	// when
+	warning1, critical1, ok1 := thresholdMap.Resolve(MustNewNumericMetric("disk_var", 0, "", nil, ""))
+	warning2, critical2, ok2 := thresholdMap.Resolve(MustNewNumericMetric("disk_tmp", 0, "", nil, ""))
+	warning3, critical3, ok3 := thresholdMap.Resolve(MustNewNumericMetric("net_eth0", 0, "", nil, ""))
+	warning4, critical4, ok4 := thresholdMap.Resolve(MustNewNumericMetric("load", 0, "", nil, "", MetricLabel("tier", "db")))
+	warning5, critical5, ok5 := thresholdMap.Resolve(MustNewNumericMetric("load", 0, "", nil, ""))
+
+	// then
+	assert.True(t, ok1 && ok2 && ok3 && ok4 && ok5)
+	assert.Equal(t, NewOptionalBounds(bounds1), warning1)
+	assert.Equal(t, OptionalBounds{}, critical1)
+	assert.Equal(t, NewOptionalBounds(bounds2), warning2)
+	assert.Equal(t, OptionalBounds{}, critical2)
+	assert.Equal(t, OptionalBounds{}, warning3)
+	assert.Equal(t, NewOptionalBounds(bounds3), critical3)
+	assert.Equal(t, NewOptionalBounds(bounds4), warning4)
+	assert.Equal(t, NewOptionalBounds(bounds4), critical4)
+	assert.Equal(t, OptionalBounds{}, warning5)
+	assert.Equal(t, NewOptionalBounds(bounds5), critical5)
+}
+
+func TestThresholdMap_Resolve_NoMatch(t *testing.T) {
+	// given
+	bounds := NewBounds(UpperBound(10))
+	thresholdMap := NewThresholdMap(ThresholdExact("disk_var", &bounds, nil))
+
+	// when
+	_, _, ok := thresholdMap.Resolve(MustNewNumericMetric("disk_tmp", 0, "", nil, ""))
+
+	// then
+	assert.False(t, ok)
+}
+
+func TestParseThresholdMap(t *testing.T) {
+	// when
+	thresholdMap, err := ParseThresholdMap("/var=80:90,/srv/*=:95,~^/mnt/=10:20;5:30,*=70:80", ThresholdSubjectLabel("mount"))
+
+	// then
+	assert.NoError(t, err)
+
+	resolve := func(mount string) (string, string) {
+		metric := MustNewNumericMetric("{mount}_used", 0, "%", nil, "", MetricLabel("mount", mount))
+		warning, critical, ok := thresholdMap.Resolve(metric)
+		assert.True(t, ok)
+
+		return warning.OrElse(NewBounds()).ToNagiosRange(), critical.OrElse(NewBounds()).ToNagiosRange()
+	}
+
+	warning1, critical1 := resolve("/var")
+	warning2, critical2 := resolve("/srv/data")
+	warning3, critical3 := resolve("/mnt/backup")
+	warning4, critical4 := resolve("/")
+
+	assert.Equal(t, ":80", warning1)
+	assert.Equal(t, ":90", critical1)
+	assert.Equal(t, "", warning2)
+	assert.Equal(t, ":95", critical2)
+	assert.Equal(t, "10:20", warning3)
+	assert.Equal(t, "5:30", critical3)
+	assert.Equal(t, ":70", warning4)
+	assert.Equal(t, ":80", critical4)
+}
+
+func TestParseThresholdMap_Invalid(t *testing.T) {
+	// when
+	_, err1 := ParseThresholdMap("/var")
+	_, err2 := ParseThresholdMap("/var=a:b")
+	_, err3 := ParseThresholdMap("/var=1:2:3")
+	_, err4 := ParseThresholdMap("~(=1:2")
+	_, err5 := ParseThresholdMap("[=1:2")
+	thresholdMap, err6 := ParseThresholdMap("")
+
+	// then
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+	assert.Error(t, err3)
+	assert.Error(t, err4)
+	assert.Error(t, err5)
+	assert.NoError(t, err6)
+	assert.Implements(t, (*ThresholdMap)(nil), thresholdMap)
+}
@@ -0,0 +1,89 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"math"
+)
+
+// UndefinedPerfDataMode decides how performance data gets rendered for metrics with an undefined value
+type UndefinedPerfDataMode int
+
+// Performance data modes for undefined values, which can be passed to ContextUndefinedValue() and
+// CheckUndefinedValue()
+const (
+	// UndefinedPerfDataEmit emits performance data with "U" as value, as specified by the Nagios plugin guidelines
+	UndefinedPerfDataEmit UndefinedPerfDataMode = iota
+	// UndefinedPerfDataSkip omits performance data for metrics with an undefined value
+	UndefinedPerfDataSkip
+)
+
+const undefinedValueHint = "value undefined"
+
+type undefinedValuePolicy struct {
+	state    State
+	perfData UndefinedPerfDataMode
+}
+
+// ContextUndefinedValue is a functional option for context constructors, which evaluates metrics with an undefined
+// value (NaN or ±Inf) to the given state instead of passing them to the context, e.g. StateUnknown() or StateOk().
+// Performance data for these metrics is either emitted with "U" as value or skipped. Without this option, undefined
+// values never match any threshold, so that contexts like ScalarContext return CRITICAL.
+func ContextUndefinedValue(state State, perfData UndefinedPerfDataMode) ContextOpt {
+	return func(c *baseContext) {
+		c.undefinedValuePolicy = &undefinedValuePolicy{state: state, perfData: perfData}
+	}
+}
+
+// CheckUndefinedValue is a functional option for NewCheck(), which applies the same policy as ContextUndefinedValue()
+// to all contexts of the check, unless a context has its own policy
+func CheckUndefinedValue(state State, perfData UndefinedPerfDataMode) CheckOpt {
+	return func(c *baseCheck) {
+		c.undefinedValuePolicy = &undefinedValuePolicy{state: state, perfData: perfData}
+	}
+}
+
+func isUndefinedValue(value float64) bool {
+	return math.IsNaN(value) || math.IsInf(value, 0)
+}
+
+func hasUndefinedValue(metric Metric) bool {
+	numericMetric, ok := metric.(NumericMetric)
+	return ok && isUndefinedValue(numericMetric.Value())
+}
+
+// thresholdViolationHint returns the violation hint of the given threshold or "value undefined", in case the value
+// could not match any threshold at all
+func thresholdViolationHint(threshold Bounds, value float64) string {
+	if isUndefinedValue(value) {
+		return undefinedValueHint
+	}
+
+	return threshold.ViolationHint()
+}
+
+func (c *baseCheck) resolveUndefinedValuePolicy(context Context) *undefinedValuePolicy {
+	if policyContext, ok := context.(interface{ undefinedValue() *undefinedValuePolicy }); ok {
+		if policy := policyContext.undefinedValue(); policy != nil {
+			return policy
+		}
+	}
+
+	return c.undefinedValuePolicy
+}
@@ -0,0 +1,82 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestScalarContext_UndefinedValueHint(t *testing.T) {
+	// given
+	threshold, _ := NewBoundsFromNagiosRange("10")
+	context := NewScalarContext("context", nil, &threshold)
+
+	// when
+	result := context.Evaluate(MustNewNumericMetric("metric", math.NaN(), "", nil, ""), NewResource())
+
+	// then
+	assert.Equal(t, StateCritical(), result.State().OrElse(StateOk()))
+	assert.Equal(t, "value undefined", result.Hint())
+}
+
+func TestBaseCheck_UndefinedValue(t *testing.T) {
+	// given
+	threshold, _ := NewBoundsFromNagiosRange("10")
+	check := NewCheck("check", NewSummarizer(),
+		CheckUndefinedValue(StateOk(), UndefinedPerfDataEmit),
+		CheckDefaultContext("default"),
+	)
+	check.AttachResources(&mockMetricsResource{NewResource(), []Metric{
+		MustNewNumericMetric("nan", math.NaN(), "ms", nil, "skipped"),
+		MustNewNumericMetric("inf", math.Inf(1), "ms", nil, "default"),
+		MustNewNumericMetric("regular", 5, "ms", nil, "default"),
+	}})
+	check.AttachContexts(
+		NewScalarContext("default", &threshold, nil),
+		NewScalarContext("skipped", &threshold, nil, ContextUndefinedValue(StateWarning(), UndefinedPerfDataSkip)),
+	)
+
+	// when
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateWarning(), check.State())
+	assert.Equal(t, "nan is NaNms (value undefined)", check.Summary())
+	assert.Equal(t, []string{"inf=U;:10", "regular=5ms;:10"}, perfDataStrings(check.PerfData()))
+}
+
+func TestBaseCheck_UndefinedValue_Default(t *testing.T) {
+	// given
+	threshold, _ := NewBoundsFromNagiosRange("10")
+	check := NewCheck("check", NewSummarizer())
+	check.AttachResources(&mockMetricsResource{NewResource(), []Metric{
+		MustNewNumericMetric("nan", math.NaN(), "ms", nil, "default"),
+	}})
+	check.AttachContexts(NewScalarContext("default", &threshold, nil))
+
+	// when
+	check.Run(NewWarningCollection())
+
+	// then
+	assert.Equal(t, StateCritical(), check.State())
+	assert.Equal(t, "nan is NaNms (value undefined)", check.Summary())
+	assert.Equal(t, []string{"nan=U;:10"}, perfDataStrings(check.PerfData()))
+}
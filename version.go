@@ -0,0 +1,277 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version represents a parsed software version, which can be compared to other versions. Both semantic versions and
+// the version format of Debian based distributions are supported, including epochs ("1:2.0"), pre-releases ("2.0-rc1"
+// or "2.0~rc1"), build metadata ("2.0+build.5") and revisions ("2.0-1ubuntu2"). Versions are ordered like dpkg does,
+// so semantic versions only sort as expected, as long as their pre-releases start with a letter.
+type Version interface {
+	fmt.Stringer
+
+	Compare(other Version) int
+}
+
+// VersionConstraint represents a list of version comparisons like ">=2.0 <2.3", which all have to be satisfied for a
+// version to match the constraint
+type VersionConstraint interface {
+	fmt.Stringer
+
+	Matches(version Version) bool
+}
+
+type version struct {
+	original string
+	epoch    int
+	upstream string
+	revision string
+}
+
+type versionConstraint struct {
+	specifier   string
+	comparisons []versionComparison
+}
+
+type versionComparison struct {
+	operator string
+	version  Version
+}
+
+var versionOperators = []string{"<=", ">=", "!=", "==", "=", "<", ">"}
+
+// ParseVersion parses the given version string. A leading "v" gets ignored, as well as build metadata of semantic
+// versions without Debian revision. Pre-releases of semantic versions ("-rc1") are sorted before their release, just
+// like Debian versions using a tilde ("~rc1"). Numeric pre-releases like "1.0.0-0.3.7" can not be told apart from
+// Debian revisions like "5.4.0-100.113" and are therefore sorted after their release, unless a tilde is used instead.
+func ParseVersion(value string) (Version, error) {
+	result := &version{original: value}
+	remaining := strings.TrimPrefix(strings.TrimSpace(value), "v")
+
+	if index := strings.Index(remaining, ":"); index >= 0 {
+		epoch, err := strconv.Atoi(remaining[:index])
+		if err != nil || epoch < 0 {
+			return nil, fmt.Errorf("version [%s] contains invalid epoch", value)
+		}
+		result.epoch, remaining = epoch, remaining[index+1:]
+	}
+
+	if index := strings.Index(remaining, "+"); index >= 0 && result.epoch == 0 &&
+		!strings.Contains(remaining[index:], "-") {
+		remaining = remaining[:index]
+	}
+
+	if index := strings.Index(remaining, "-"); index >= 0 && result.epoch == 0 &&
+		index+1 < len(remaining) && unicode.IsLetter(rune(remaining[index+1])) {
+		remaining = remaining[:index] + "~" + remaining[index+1:]
+	}
+
+	if index := strings.LastIndex(remaining, "-"); index >= 0 {
+		result.revision, remaining = remaining[index+1:], remaining[:index]
+	}
+	result.upstream = remaining
+
+	if result.upstream == "" || !unicode.IsDigit(rune(result.upstream[0])) {
+		return nil, fmt.Errorf("version [%s] must start with a digit", value)
+	}
+
+	return result, nil
+}
+
+// ParseVersionConstraint parses a constraint specifier consisting of one or more comparisons, separated by spaces or
+// commas. Each comparison consists of an operator (<, <=, >, >=, =, == or !=) and a version, e.g. ">=2.0 <2.3".
+func ParseVersionConstraint(specifier string) (VersionConstraint, error) {
+	tokens := strings.FieldsFunc(specifier, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+	result := &versionConstraint{specifier: strings.Join(tokens, " ")}
+
+	for index := 0; index < len(tokens); index++ {
+		token := tokens[index]
+		if isVersionOperator(token) && index+1 < len(tokens) {
+			index++
+			token += tokens[index]
+		}
+
+		comparison, err := parseVersionComparison(token)
+		if err != nil {
+			return nil, err
+		}
+		result.comparisons = append(result.comparisons, comparison)
+	}
+
+	if len(result.comparisons) == 0 {
+		return nil, errors.New("version constraint must contain at least one comparison")
+	}
+
+	return result, nil
+}
+
+func isVersionOperator(value string) bool {
+	for _, operator := range versionOperators {
+		if value == operator {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseVersionComparison(value string) (versionComparison, error) {
+	operator := "="
+	for _, candidate := range versionOperators {
+		if strings.HasPrefix(value, candidate) {
+			operator = candidate
+			break
+		}
+	}
+
+	parsedVersion, err := ParseVersion(strings.TrimPrefix(value, operator))
+	if err != nil {
+		return versionComparison{}, fmt.Errorf("invalid version constraint [%s] (%s)", value, err.Error())
+	}
+
+	return versionComparison{operator: operator, version: parsedVersion}, nil
+}
+
+func (v version) String() string {
+	return v.original
+}
+
+func (v version) Compare(other Version) int {
+	otherVersion, ok := other.(*version)
+	if !ok {
+		return compareVersionPart(v.original, other.String())
+	}
+
+	if v.epoch != otherVersion.epoch {
+		if v.epoch < otherVersion.epoch {
+			return -1
+		}
+		return 1
+	}
+
+	if result := compareVersionPart(v.upstream, otherVersion.upstream); result != 0 {
+		return result
+	}
+
+	return compareVersionPart(v.revision, otherVersion.revision)
+}
+
+// compareVersionPart compares two version parts using the algorithm of dpkg, which alternately compares non-digit parts
+// lexically (letters before non-letters, tildes before anything else) and digit parts numerically
+func compareVersionPart(a string, b string) int {
+	for len(a) > 0 || len(b) > 0 {
+		for (len(a) > 0 && !isDigit(a[0])) || (len(b) > 0 && !isDigit(b[0])) {
+			orderA, orderB := versionCharOrder(a), versionCharOrder(b)
+			if orderA != orderB {
+				return sign(orderA - orderB)
+			}
+			a, b = a[1:], b[1:]
+		}
+
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		firstDifference := 0
+		for len(a) > 0 && isDigit(a[0]) && len(b) > 0 && isDigit(b[0]) {
+			if firstDifference == 0 {
+				firstDifference = int(a[0]) - int(b[0])
+			}
+			a, b = a[1:], b[1:]
+		}
+
+		if len(a) > 0 && isDigit(a[0]) {
+			return 1
+		}
+		if len(b) > 0 && isDigit(b[0]) {
+			return -1
+		}
+		if firstDifference != 0 {
+			return sign(firstDifference)
+		}
+	}
+
+	return 0
+}
+
+func versionCharOrder(value string) int {
+	if len(value) == 0 || isDigit(value[0]) {
+		return 0
+	}
+
+	switch char := value[0]; {
+	case char == '~':
+		return -1
+	case (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z'):
+		return int(char)
+	default:
+		return int(char) + 256
+	}
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func sign(value int) int {
+	if value < 0 {
+		return -1
+	} else if value > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func (c versionConstraint) String() string {
+	return c.specifier
+}
+
+func (c versionConstraint) Matches(version Version) bool {
+	for _, comparison := range c.comparisons {
+		if !comparison.matches(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c versionComparison) matches(version Version) bool {
+	result := version.Compare(c.version)
+
+	switch c.operator {
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "!=":
+		return result != 0
+	default:
+		return result == 0
+	}
+}
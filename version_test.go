@@ -0,0 +1,133 @@
+/*
+ * nagopher - Library for writing Nagios plugins in Go
+ * Copyright (C) 2018-2019  Pascal Mathis
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nagopher
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVersion_Compare(t *testing.T) {
+	testCases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.10", "1.2.9", 1},
+		{"1.2", "1.2.0", -1},
+		{"1.1.1k", "1.1.1j", 1},
+		{"1.1.1", "1.1.1a", -1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0~rc1", "1.0.0", -1},
+		{"1.0.0+build.5", "1.0.0+build.7", 0},
+		{"1:1.0", "2.0", 1},
+		{"2:1.0", "1:9.9", 1},
+		{"2.0-1", "2.0-2", -1},
+		{"2.0-1ubuntu2", "2.0-1ubuntu10", -1},
+		{"2.0+dfsg-1", "2.0-1", 1},
+		{"5.4.0-100", "5.4.0-99", 1},
+		{"1.0.0-0.3.7", "1.0.0", 1},
+		{"1.0.0~0.3.7", "1.0.0", -1},
+		{"007", "7", 0},
+	}
+
+	for _, testCase := range testCases {
+		// given
+		a, errA := ParseVersion(testCase.a)
+		b, errB := ParseVersion(testCase.b)
+
+		// when
+		result := a.Compare(b)
+		reverse := b.Compare(a)
+
+		// then
+		assert.NoError(t, errA, testCase.a)
+		assert.NoError(t, errB, testCase.b)
+		assert.Equal(t, testCase.expected, result, testCase.a+" <> "+testCase.b)
+		assert.Equal(t, -testCase.expected, reverse, testCase.b+" <> "+testCase.a)
+	}
+}
+
+func TestParseVersion_Invalid(t *testing.T) {
+	for _, value := range []string{"", "abc", "x:1.0", "-1:1.0", "1:"} {
+		// when
+		_, err := ParseVersion(value)
+
+		// then
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseVersionConstraint(t *testing.T) {
+	// given
+	constraint, err := ParseVersionConstraint(">= 2.0, <2.3")
+	version1, _ := ParseVersion("2.1")
+	version2, _ := ParseVersion("1.9")
+	version3, _ := ParseVersion("2.3-rc1")
+	version4, _ := ParseVersion("2.3")
+
+	// when
+	ok1 := constraint.Matches(version1)
+	ok2 := constraint.Matches(version2)
+	ok3 := constraint.Matches(version3)
+	ok4 := constraint.Matches(version4)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, ">= 2.0 <2.3", constraint.String())
+	assert.True(t, ok1)
+	assert.False(t, ok2)
+	assert.True(t, ok3)
+	assert.False(t, ok4)
+}
+
+func TestParseVersionConstraint_Operators(t *testing.T) {
+	// given
+	version, _ := ParseVersion("1.1.1k")
+	specifiers := map[string]bool{
+		"1.1.1k": true, "=1.1.1k": true, "==1.1.1k": true, "!=1.1.1k": false,
+		">1.1.1j": true, "<1.1.1k": false, "<=1.1.1k": true, ">=1.1.1l": false,
+	}
+
+	for specifier, expected := range specifiers {
+		// when
+		constraint, err := ParseVersionConstraint(specifier)
+		ok := constraint.Matches(version)
+
+		// then
+		assert.NoError(t, err, specifier)
+		assert.Equal(t, expected, ok, specifier)
+	}
+}
+
+func TestParseVersionConstraint_Invalid(t *testing.T) {
+	// when
+	_, err1 := ParseVersionConstraint("")
+	_, err2 := ParseVersionConstraint(">=2.0 <")
+	_, err3 := ParseVersionConstraint(">=two")
+
+	// then
+	assert.EqualError(t, err1, "version constraint must contain at least one comparison")
+	assert.EqualError(t, err2, "invalid version constraint [<] (version [] must start with a digit)")
+	assert.EqualError(t, err3, "invalid version constraint [>=two] (version [two] must start with a digit)")
+}